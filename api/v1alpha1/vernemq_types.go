@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -72,9 +73,15 @@ type VerneMQSpec struct {
 	// object, which shall be mounted into the VerneMQ Pods.
 	// The ConfigMaps are mounted into /etc/vernemq/configmaps/<configmap-name>.
 	ConfigMaps []string `json:"configMaps,omitempty"`
-	// Defines the config that is used when starting VerneMQ (similar to vernemq.conf)
+	// Defines typed settings that are rendered into vernemq.conf when starting VerneMQ
+	StaticConfig *StaticConfig `json:"staticConfig,omitempty"`
+	// Defines additional config that is used when starting VerneMQ (similar to vernemq.conf).
+	// Keys that are managed by the operator or set via StaticConfig must not be repeated here.
 	VMQConfig string `json:"vmqConfig,omitempty"`
-	// Defines the arguments passed to the erlang VM when starting VerneMQ
+	// Defines typed settings that are rendered into vm.args when starting VerneMQ
+	ErlangVM *ErlangVMConfig `json:"erlangVM,omitempty"`
+	// Defines the arguments passed to the erlang VM when starting VerneMQ.
+	// Each line is merged with the defaults of the operator by its flag.
 	VMArgs string `json:"vmArgs,omitempty"`
 	// Defines additional environment variables for the VerneMQ container
 	// The environment variables can be used to template the VMQConfig and VMArgs
@@ -94,10 +101,264 @@ type VerneMQSpec struct {
 	BundlerImage *string `json:"bundlerImage,omitempty"`
 	// Bundler Base image to use for a VerneMQ Plugin Bundler deployment.
 	BundlerBaseImage string `json:"bundlerBaseImage,omitempty"`
+	// Bundler configures the builds of the Plugin Bundler
+	Bundler *BundlerSpec `json:"bundler,omitempty"`
+	// PluginBundle provides the plugins from a prebuilt image instead of the Plugin Bundler.
+	// No Plugin Bundler is deployed if set, so ExternalPlugins and Bundler must not be set.
+	PluginBundle *PluginBundleSpec `json:"pluginBundle,omitempty"`
+	// PluginSelector selects the VerneMQPlugin objects in the namespace of the
+	// VerneMQ object to build and enable. No VerneMQPlugins are selected if unset.
+	PluginSelector *metav1.LabelSelector `json:"pluginSelector,omitempty"`
 	// Defines external plugins that have to be compiled and loaded into VerneMQ
 	ExternalPlugins []PluginSource `json:"externalPlugins,omitempty"`
 	// Defines the reloadable config that VerneMQ regularly checks and applies
 	Config ReloadableConfig `json:"config,omitempty"`
+	// Auth enables the authentication and authorization of clients
+	Auth *AuthSpec `json:"auth,omitempty"`
+	// Webhooks registers endpoints with the vmq_webhooks plugin. They are applied
+	// as part of the reloadable config, so webhooks can be added and removed without
+	// restarting VerneMQ. vmq_webhooks must not be declared in config.plugins as well.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Federation connects the VerneMQ cluster with the VerneMQ clusters managed by
+	// peer operators in other Kubernetes clusters by bridging the federated topics
+	Federation *FederationSpec `json:"federation,omitempty"`
+	// Monitoring exposes the metrics of VerneMQ to the Prometheus Operator
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+	// Probes overrides the settings of the probes of the VerneMQ container
+	Probes *ProbesSpec `json:"probes,omitempty"`
+	// PodDisruptionBudget configures the PodDisruptionBudget of the VerneMQ pods
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// Topology spreads the VerneMQ pods across zones or hosts. The generated
+	// pod anti-affinity is merged with Affinity.
+	Topology *TopologySpec `json:"topology,omitempty"`
+}
+
+// TopologySpec defines a preset policy for spreading the VerneMQ pods
+// +k8s:openapi-gen=true
+type TopologySpec struct {
+	// Spread selects the topology domains the pods are spread across, can be
+	// "Zone", "Host" or "ZoneAndHost". Pods are kept off hosts running another
+	// VerneMQ pod of the instance for "Host" and "ZoneAndHost".
+	// +kubebuilder:validation:Enum=Zone;Host;ZoneAndHost
+	Spread string `json:"spread"`
+	// Mode "Required" doesn't schedule pods violating the policy, "Preferred" only
+	// prefers nodes satisfying it. Defaults to "Preferred".
+	// +kubebuilder:validation:Enum=Required;Preferred
+	Mode string `json:"mode,omitempty"`
+	// MaxSkew is the maximum difference of the number of pods between two
+	// domains. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	MaxSkew *int32 `json:"maxSkew,omitempty"`
+}
+
+const (
+	TopologySpreadZone        = "Zone"
+	TopologySpreadHost        = "Host"
+	TopologySpreadZoneAndHost = "ZoneAndHost"
+
+	TopologyModeRequired  = "Required"
+	TopologyModePreferred = "Preferred"
+)
+
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget of the VerneMQ pods. No
+// voluntary disruptions are allowed while the StatefulSet is rolling or the cluster
+// is partitioned, i.e. the running nodes report different cluster views. A second
+// PodDisruptionBudget allows the eviction of one Plugin Bundler pod at a time.
+// +k8s:openapi-gen=true
+type PodDisruptionBudgetSpec struct {
+	// Disabled removes the PodDisruptionBudgets of the VerneMQ and Plugin Bundler pods
+	Disabled bool `json:"disabled,omitempty"`
+	// MaxUnavailable is the number or percentage of VerneMQ pods that may be
+	// evicted at the same time. Defaults to 1.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// ProbesSpec defines the probes of the VerneMQ container. The startup probe covers
+// the recovery of the message store, the liveness and readiness probes only start
+// once it succeeded.
+// +k8s:openapi-gen=true
+type ProbesSpec struct {
+	// Startup overrides the settings of the startup probe. By default VerneMQ
+	// may take up to 10 minutes to start.
+	Startup *ProbeSettings `json:"startup,omitempty"`
+	// Liveness overrides the settings of the liveness probe
+	Liveness *ProbeSettings `json:"liveness,omitempty"`
+	// Readiness overrides the settings of the readiness probe
+	Readiness *ProbeSettings `json:"readiness,omitempty"`
+	// ReadinessMode selects what the readiness probe checks. "Health" checks the
+	// /health endpoint of the node. "ClusterJoined" additionally requires the node to
+	// have finished starting and loading its queues and to see a majority of the nodes
+	// in the cluster view running. In clusters of up to two nodes a node doesn't wait
+	// for its peer. Defaults to "Health".
+	// +kubebuilder:validation:Enum=Health;ClusterJoined
+	ReadinessMode string `json:"readinessMode,omitempty"`
+}
+
+// ProbeSettings defines the timing of a probe, unset fields keep the defaults of the operator
+// +k8s:openapi-gen=true
+type ProbeSettings struct {
+	// Number of seconds after the container has started before the probe is initiated
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// How often in seconds to perform the probe
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// Number of seconds after which the probe times out
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// Minimum consecutive successes for the probe to be considered successful after having failed
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+	// Minimum consecutive failures for the probe to be considered failed after having succeeded
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+const (
+	ReadinessModeHealth        = "Health"
+	ReadinessModeClusterJoined = "ClusterJoined"
+)
+
+// MonitoringSpec defines how the metrics of the VerneMQ pods are scraped. The
+// Prometheus Operator objects are only created if their CRDs are installed.
+// +k8s:openapi-gen=true
+type MonitoringSpec struct {
+	// Kind of the Prometheus Operator object selecting the VerneMQ pods,
+	// can be "ServiceMonitor" or "PodMonitor". Defaults to "ServiceMonitor".
+	// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+	Kind string `json:"kind,omitempty"`
+	// Interval at which the metrics are scraped, e.g. "30s". Defaults to the
+	// interval of Prometheus.
+	Interval string `json:"interval,omitempty"`
+	// Labels added to the ServiceMonitor or PodMonitor and the PrometheusRule,
+	// e.g. to match the selectors of Prometheus
+	Labels map[string]string `json:"labels,omitempty"`
+	// Alerts ships a PrometheusRule with alerts for the VerneMQ cluster
+	Alerts *AlertsSpec `json:"alerts,omitempty"`
+}
+
+// AlertsSpec defines the alerts of the PrometheusRule
+// +k8s:openapi-gen=true
+type AlertsSpec struct {
+	// For is the time a condition has to hold before an alert fires. Defaults to "5m".
+	For string `json:"for,omitempty"`
+	// QueueGrowthRate is the rate in messages per second at which the queues of a
+	// node may grow before an alert fires. Defaults to 100.
+	QueueGrowthRate *int32 `json:"queueGrowthRate,omitempty"`
+	// Labels added to every alert, e.g. a severity
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// FederationSpec defines the membership of the VerneMQ cluster in a federation.
+// The operator publishes the endpoint of this member in the Secret
+// vernemq-<name>-federation-member, but it doesn't exchange endpoints with the
+// operators of other clusters itself: the keys of the member Secret of every member
+// have to be copied into the peers Secret of all members, by hand or e.g. by a
+// secret replication tool. Every node bridges the federated topics to each peer,
+// changing the peers restarts the VerneMQ pods.
+// +k8s:openapi-gen=true
+type FederationSpec struct {
+	// Name of this member, unique within the federation
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Advertise is the MQTT endpoint the peers connect to, which must be reachable
+	// from the other Kubernetes clusters, e.g. a LoadBalancer Service
+	Advertise FederationEndpoint `json:"advertise"`
+	// PeersSecret references a Secret in the namespace of the VerneMQ object that
+	// holds the endpoints of the peers as "host:port" by their member name. The entry
+	// of this member is ignored, invalid entries are skipped and reported in the
+	// federation status. The bridges
+	// to the peers are part of vernemq.conf, so every change of the peers rolls all
+	// VerneMQ pods.
+	PeersSecret v1.LocalObjectReference `json:"peersSecret"`
+	// TLS connects to the peers using TLS
+	TLS *BridgeTLS `json:"tls,omitempty"`
+	// CredentialsSecret references a Secret with the keys "username" and "password"
+	// used to connect to the peers
+	CredentialsSecret *v1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+	// Topics are the topic mappings of the bridges to each peer
+	// +kubebuilder:validation:MinItems=1
+	Topics []BridgeTopic `json:"topics"`
+}
+
+// FederationEndpoint defines the MQTT endpoint of a federation member
+// +k8s:openapi-gen=true
+type FederationEndpoint struct {
+	// Host of the endpoint, e.g. the address of a LoadBalancer Service
+	Host string `json:"host"`
+	// Port of the endpoint. Defaults to 1883.
+	Port *int32 `json:"port,omitempty"`
+}
+
+// Webhook registers an HTTP endpoint that is called for a plugin hook
+// +k8s:openapi-gen=true
+type Webhook struct {
+	// Hook the endpoint is called for
+	// +kubebuilder:validation:Enum=auth_on_register;auth_on_publish;auth_on_subscribe;on_register;on_publish;on_subscribe;on_unsubscribe;on_deliver;on_offline_message;on_client_wakeup;on_client_offline;on_client_gone;on_session_expired;auth_on_register_m5;auth_on_publish_m5;auth_on_subscribe_m5;on_register_m5;on_publish_m5;on_subscribe_m5;on_unsubscribe_m5;on_deliver_m5;on_auth_m5
+	Hook string `json:"hook"`
+	// Endpoint is the HTTP(S) URL of the webhook
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+	// Base64Payload encodes the MQTT payload base64. Defaults to true.
+	Base64Payload *bool `json:"base64Payload,omitempty"`
+	// NoPayload omits the MQTT payload of auth_on_publish and auth_on_publish_m5 calls
+	NoPayload bool `json:"noPayload,omitempty"`
+	// BasicAuthSecretRef references a Secret in the namespace of the VerneMQ object with
+	// the keys "username" and "password", which are sent as basic Authorization header.
+	// The credentials are mounted into the VerneMQ pods and never part of the pod spec.
+	// vmq_webhooks doesn't support other headers.
+	BasicAuthSecretRef *v1.LocalObjectReference `json:"basicAuthSecretRef,omitempty"`
+}
+
+// AuthSpec configures the authentication and authorization plugins
+// +k8s:openapi-gen=true
+type AuthSpec struct {
+	// UserSelector selects the VerneMQUser objects in the namespace of the VerneMQ object,
+	// which are compiled into the password file of the vmq_passwd plugin. vmq_passwd is only
+	// enabled if UserSelector is set.
+	UserSelector *metav1.LabelSelector `json:"userSelector,omitempty"`
+	// ACLSelector selects the VerneMQACL objects in the namespace of the VerneMQ object,
+	// which are compiled into the ACL file of the vmq_acl plugin. vmq_acl is only enabled
+	// if ACLSelector is set.
+	ACLSelector *metav1.LabelSelector `json:"aclSelector,omitempty"`
+	// Diversity authenticates clients against a database with the vmq_diversity plugin
+	Diversity *DiversitySpec `json:"diversity,omitempty"`
+}
+
+// DiversitySpec configures the database backend of the vmq_diversity plugin
+// +k8s:openapi-gen=true
+type DiversitySpec struct {
+	// Backend is the database the clients are authenticated against
+	// +kubebuilder:validation:Enum=postgres;cockroachdb;mysql;mongodb;redis
+	Backend string `json:"backend"`
+	// Host of the database
+	Host string `json:"host"`
+	// Port of the database. Defaults to the default port of the backend.
+	Port *int32 `json:"port,omitempty"`
+	// Database name, or database number for redis
+	Database string `json:"database,omitempty"`
+	// Size of the connection pool
+	PoolSize *int32 `json:"poolSize,omitempty"`
+	// Connect to postgres and cockroachdb using SSL
+	SSL *bool `json:"ssl,omitempty"`
+	// PasswordHashMethod of the passwords stored in the database. Supported are crypt, bcrypt
+	// and sha256 by postgres, bcrypt and sha256 by cockroachdb and password, md5, sha1 and
+	// sha256 by mysql. mongodb and redis don't support it.
+	// +kubebuilder:validation:Enum=crypt;bcrypt;sha256;password;md5;sha1
+	PasswordHashMethod string `json:"passwordHashMethod,omitempty"`
+	// AuthSource is the mongodb database the login is defined in
+	AuthSource string `json:"authSource,omitempty"`
+	// CredentialsSecret references a Secret in the namespace of the VerneMQ object with the
+	// keys "username" and "password" used to connect to the database, redis only uses the
+	// password. The Secret is mounted into the VerneMQ pods and the credentials are only
+	// written into vernemq.conf when the pods start.
+	CredentialsSecret *v1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+	// Scripts mounts Lua scripts from a ConfigMap and loads them into vmq_diversity
+	Scripts *LuaScripts `json:"scripts,omitempty"`
+}
+
+// LuaScripts references Lua scripts stored in a ConfigMap
+// +k8s:openapi-gen=true
+type LuaScripts struct {
+	// Name of the ConfigMap in the namespace of the VerneMQ object
+	Name string `json:"name"`
+	// Keys of the ConfigMap holding the scripts to load
+	Keys []string `json:"keys"`
 }
 
 // ReloadableConfig defines the reloadable parts of the VerneMQ configuration
@@ -111,17 +372,193 @@ type ReloadableConfig struct {
 	Configs []ConfigItem `json:"configs,omitempty"`
 }
 
+// StaticConfig defines the commonly used vernemq.conf settings that can't be changed on runtime
+// +k8s:openapi-gen=true
+type StaticConfig struct {
+	// Allow clients to connect without authentication
+	AllowAnonymous *bool `json:"allowAnonymous,omitempty"`
+	// The maximum number of QoS 1 or 2 messages that can be in the process of being
+	// transmitted simultaneously. Set to 0 for no limit.
+	MaxInflightMessages *int32 `json:"maxInflightMessages,omitempty"`
+	// The maximum number of messages to hold in the queue of an online client. Set to -1 for no limit.
+	MaxOnlineMessages *int32 `json:"maxOnlineMessages,omitempty"`
+	// The maximum number of messages to hold in the queue of an offline client. Set to -1 for no limit.
+	MaxOfflineMessages *int32 `json:"maxOfflineMessages,omitempty"`
+	// Specifies how queues should process messages, can be "fifo" or "lifo"
+	// +kubebuilder:validation:Enum=fifo;lifo
+	QueueType string `json:"queueType,omitempty"`
+	// Specifies how messages are delivered when multiple sessions share a queue, can be "fanout" or "balance"
+	// +kubebuilder:validation:Enum=fanout;balance
+	QueueDeliverMode string `json:"queueDeliverMode,omitempty"`
+	// Defines how long offline persistent sessions are kept before they are removed, e.g. "1w".
+	// Defaults to "never".
+	PersistentClientExpiration string `json:"persistentClientExpiration,omitempty"`
+	// Defines the policy used to pick a subscriber of a shared subscription,
+	// can be "prefer_local", "local_only" or "random"
+	// +kubebuilder:validation:Enum=prefer_local;local_only;random
+	SharedSubscriptionPolicy string `json:"sharedSubscriptionPolicy,omitempty"`
+	// Configures the LevelDB message store
+	LevelDB *LevelDBConfig `json:"leveldb,omitempty"`
+	// Configures logging
+	Logging *LoggingConfig `json:"logging,omitempty"`
+}
+
+// LevelDBConfig defines the settings of the LevelDB message store
+// +k8s:openapi-gen=true
+type LevelDBConfig struct {
+	// The percentage of total memory LevelDB is allowed to use. Defaults to 20.
+	MaximumMemoryPercent *int32 `json:"maximumMemoryPercent,omitempty"`
+	// The lower bound of the randomized write buffer size in bytes
+	WriteBufferSizeMin *int64 `json:"writeBufferSizeMin,omitempty"`
+	// The upper bound of the randomized write buffer size in bytes
+	WriteBufferSizeMax *int64 `json:"writeBufferSizeMax,omitempty"`
+}
+
+// LoggingConfig defines the log settings of VerneMQ
+// +k8s:openapi-gen=true
+type LoggingConfig struct {
+	// Where to emit the console log, can be "off", "file", "console" or "both". Defaults to "console".
+	// +kubebuilder:validation:Enum=off;file;console;both
+	Console string `json:"console,omitempty"`
+	// The severity level of the console log, can be "debug", "info", "warning" or "error"
+	// +kubebuilder:validation:Enum=debug;info;warning;error
+	ConsoleLevel string `json:"consoleLevel,omitempty"`
+	// Whether to write a crash log
+	CrashLog *bool `json:"crashLog,omitempty"`
+}
+
+// ErlangVMConfig defines the commonly used vm.args settings. Settings are merged with
+// the defaults of the operator and the lines of VMArgs by their flag, ErlangVMConfig
+// takes precedence over VMArgs.
+// +k8s:openapi-gen=true
+type ErlangVMConfig struct {
+	// The maximum number of simultaneously existing Erlang processes (+P). Defaults to 256000.
+	ProcessLimit *int32 `json:"processLimit,omitempty"`
+	// The number of async threads (+A). Defaults to 64.
+	AsyncThreads *int32 `json:"asyncThreads,omitempty"`
+	// The number of scheduler threads (+S). Defaults to the CPU limit of the VerneMQ container
+	// or, if no limit is set, to the number of available cores.
+	Schedulers *int32 `json:"schedulers,omitempty"`
+	// The number of schedulers online (+S). Defaults to the number of schedulers, which it must not exceed.
+	SchedulersOnline *int32 `json:"schedulersOnline,omitempty"`
+	// The scheduler busy wait threshold (+sbwt), can be "none", "very_short", "short",
+	// "medium", "long" or "very_long"
+	// +kubebuilder:validation:Enum=none;very_short;short;medium;long;very_long
+	SchedulerBusyWait string `json:"schedulerBusyWait,omitempty"`
+	// The distribution buffer busy limit in kilobytes (+zdbbl)
+	DistributionBufferSize *int32 `json:"distributionBufferSize,omitempty"`
+	// Enables kernel poll (+K). Defaults to true.
+	KernelPoll *bool `json:"kernelPoll,omitempty"`
+	// Additional vm.args lines, e.g. "+sbt db". They take precedence over all other settings.
+	ExtraFlags []string `json:"extraFlags,omitempty"`
+}
+
 // PluginSource defines the plugins to be fetched, compiled and loaded into the VerneMQ container
 // +k8s:openapi-gen=true
 type PluginSource struct {
 	// The name of the plugin application
-	ApplicationName string `json:"applicationName"`
-	// The URL of the Git repository
-	RepoURL string `json:"repoURL"`
-	// The type to checkout, can be "branch", "tag", or "commit"
-	VersionType string `json:"versionType"`
+	ApplicationName  string `json:"applicationName"`
+	PluginSourceSpec `json:",inline"`
+}
+
+// PluginSourceSpec defines where the source of a plugin is fetched from
+// +k8s:openapi-gen=true
+type PluginSourceSpec struct {
+	// The URL of the Git repository. Exactly one of RepoURL, Hex, Tarball and ConfigMap must be set.
+	RepoURL string `json:"repoURL,omitempty"`
+	// The type to checkout, can be "branch", "tag", or "ref" for a Git commit. "commit" is
+	// accepted as an alias of "ref" for objects created before "ref" was introduced.
+	// +kubebuilder:validation:Enum=branch;tag;ref;commit
+	VersionType string `json:"versionType,omitempty"`
 	// The version to checkout, can be name of the branch or tag, or the Git commit ref
-	Version string `json:"version"`
+	Version string `json:"version,omitempty"`
+	// Hex fetches the plugin as a package from hex.pm
+	Hex *HexSource `json:"hex,omitempty"`
+	// Tarball fetches the plugin source from a gzipped tarball
+	Tarball *TarballSource `json:"tarball,omitempty"`
+	// ConfigMap provides the plugin source as a gzipped tarball stored in a ConfigMap
+	ConfigMap *ConfigMapSource `json:"configMap,omitempty"`
+	// SecretRef references a Secret in the same namespace holding the credentials for
+	// a private Git repository. For SSH URLs the Secret must contain the keys "ssh-privatekey"
+	// and "known_hosts", for HTTPS URLs the keys "username" and "password", where the
+	// password may also be an access token.
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// BundlerSpec configures the Plugin Bundler
+// +k8s:openapi-gen=true
+type BundlerSpec struct {
+	// VmqK8s overrides the source of the vmq_k8s plugin, which defaults to the
+	// release of vmq-operator matching the operator version. The application name is ignored.
+	// The plugin must write the node reports read by the operator, tags older than
+	// v2.0.0 are rejected.
+	VmqK8s *PluginSource `json:"vmqK8s,omitempty"`
+	// Replicas of the Plugin Bundler Deployment. Defaults to 1. Every replica builds
+	// the bundle itself, so the builds must be reproducible, see docs/bundler-api.md.
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources requests and limits of the Plugin Bundler container
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Define which Nodes the Plugin Bundler Pods are scheduled on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// If specified, the Plugin Bundler Pods' tolerations.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// If specified, the Plugin Bundler Pods' scheduling constraints.
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// References to secrets for pulling the Plugin Bundler image, in addition
+	// to the imagePullSecrets of the VerneMQ spec
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// SecurityContext holds the pod-level security attributes of the Plugin Bundler Pods
+	SecurityContext *v1.PodSecurityContext `json:"securityContext,omitempty"`
+	// Cache is the volume rebar3 caches fetched dependencies in, so that rebuilds
+	// don't fetch every dependency again. Defaults to an emptyDir, which only
+	// survives container restarts; use a persistentVolumeClaim to keep the cache
+	// across rebuilds for a changed config.
+	Cache *v1.VolumeSource `json:"cache,omitempty"`
+}
+
+// PluginBundleSpec defines an image containing prebuilt plugins
+// +k8s:openapi-gen=true
+type PluginBundleSpec struct {
+	// The image containing the plugins. It must provide a shell with `cp`.
+	Image string `json:"image"`
+	// Image pull policy of the bundle image
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Path of the plugins in the image, laid out like the _build/default dir of the
+	// Plugin Bundler. Defaults to "/plugins".
+	Path string `json:"path,omitempty"`
+}
+
+// HexSource defines a plugin fetched from hex.pm
+// +k8s:openapi-gen=true
+type HexSource struct {
+	// The name of the hex package, defaults to the application name
+	Package string `json:"package,omitempty"`
+	// The version or version constraint of the package, e.g. "~> 1.2"
+	Version string `json:"version,omitempty"`
+}
+
+// TarballSource defines a plugin fetched from a gzipped tarball
+// +k8s:openapi-gen=true
+type TarballSource struct {
+	// The HTTPS URL of the tarball
+	// +kubebuilder:validation:Pattern=`^https://`
+	URL string `json:"url"`
+	// The SHA-256 checksum the tarball is verified against
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	SHA256 string `json:"sha256"`
+}
+
+// ConfigMapSource defines a plugin provided as a gzipped tarball in a ConfigMap
+// +k8s:openapi-gen=true
+type ConfigMapSource struct {
+	// The name of the ConfigMap in the namespace of the VerneMQ object
+	Name string `json:"name"`
+	// The key of the tarball in the binaryData of the ConfigMap
+	Key string `json:"key"`
+	// Digest is the SHA-256 of the tarball, resolved from the ConfigMap by the
+	// operator on every reconcile. It is not part of the API.
+	Digest string `json:"-"`
 }
 
 // Plugin defines the plugins to be enabled by VerneMQ
@@ -226,12 +663,122 @@ type VerneMQStatus struct {
 
 	// Nodes are the names of the VerneMQ pods
 	Nodes []string `json:"nodes"`
+	// ClusterView lists the VerneMQ pods the nodes last reported as running cluster members
+	ClusterView []string `json:"clusterView,omitempty"`
+	// Conditions describe the current state of the VerneMQ deployment
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConfigHash is the SHA-256 hash of the reloadable config the nodes are expected to apply
+	ConfigHash string `json:"configHash,omitempty"`
+	// ConfigStatus reports the reloadable config applied by each node
+	ConfigStatus []NodeConfigStatus `json:"configStatus,omitempty"`
+	// Bundle reports the plugin bundle provided to the VerneMQ pods
+	Bundle *BundleStatus `json:"bundle,omitempty"`
+	// Federation reports the links to the peers of the federation
+	Federation []FederationPeerStatus `json:"federation,omitempty"`
+}
+
+// FederationPeerStatus defines the state of the link to a federation peer
+// +k8s:openapi-gen=true
+type FederationPeerStatus struct {
+	// Peer is the member name of the peer
+	Peer string `json:"peer"`
+	// Endpoint of the peer
+	Endpoint string `json:"endpoint"`
+	// Message explains why no bridge to the peer is configured, e.g. an invalid
+	// endpoint in the peers Secret
+	Message string `json:"message,omitempty"`
+	// Nodes reports the state of the bridge to the peer on each VerneMQ node
+	Nodes []BridgeNodeStatus `json:"nodes,omitempty"`
+	// Connected is true if the bridge to the peer is connected on all VerneMQ nodes
+	Connected bool `json:"connected"`
+}
+
+// BundleStatus defines the observed state of the plugin bundle
+// +k8s:openapi-gen=true
+type BundleStatus struct {
+	// Digest is the SHA-256 digest of the last successfully built bundle,
+	// which the VerneMQ pods verify on startup
+	Digest string `json:"digest,omitempty"`
+	// BuildState is the state of the build for the current external plugins,
+	// can be "Building", "Succeeded" or "Failed"
+	BuildState string `json:"buildState,omitempty"`
+	// BuildTime is the time the last build finished
+	BuildTime *metav1.Time `json:"buildTime,omitempty"`
+	// Plugins lists the plugins of the last successfully built bundle
+	Plugins []BundledPlugin `json:"plugins,omitempty"`
+	// Errors lists the compile errors of the last failed build
+	Errors []string `json:"errors,omitempty"`
+}
+
+// BundledPlugin defines a plugin contained in the plugin bundle
+// +k8s:openapi-gen=true
+type BundledPlugin struct {
+	// The name of the plugin application
+	Name string `json:"name"`
+	// The version that was requested
+	Version string `json:"version,omitempty"`
+	// The commit the version was resolved to
+	Commit string `json:"commit,omitempty"`
+}
+
+const (
+	BundleBuilding  = "Building"
+	BundleSucceeded = "Succeeded"
+	BundleFailed    = "Failed"
+)
+
+// NodeConfigStatus defines the reloadable config applied by a single VerneMQ node.
+// The vmq_k8s plugin of each node writes it as JSON into the config status ConfigMap,
+// using the name of the pod as key. The operator grants the ServiceAccount of the VerneMQ
+// pods access to that ConfigMap, see docs/node-reports.md.
+// +k8s:openapi-gen=true
+type NodeConfigStatus struct {
+	// Node is the name of the VerneMQ pod
+	Node string `json:"node"`
+	// AppliedConfigHash is the SHA-256 hash of the config.yaml last applied by the node
+	AppliedConfigHash string `json:"appliedConfigHash,omitempty"`
+	// Failures lists the items of the reloadable config the node failed to apply
+	Failures []ConfigApplyFailure `json:"failures,omitempty"`
 }
 
+// ConfigApplyFailure defines a reloadable config item that couldn't be applied
+// +k8s:openapi-gen=true
+type ConfigApplyFailure struct {
+	// Kind of the item, can be "plugin", "listener" or "config"
+	Kind string `json:"kind"`
+	// Name of the plugin, the listener address or the config item
+	Name string `json:"name"`
+	// Command is the vmq-admin command that failed
+	Command string `json:"command,omitempty"`
+	// Message returned by the failed command
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	// ConditionConfigInvalid is true if the VerneMQ configuration was rejected
+	// and the StatefulSet was not updated
+	ConditionConfigInvalid = "ConfigInvalid"
+	// ConditionUnknownConfigKeys is true if vernemq.conf or the reloadable config
+	// use keys that are not in the cuttlefish schema of the VerneMQ version. The
+	// configuration is rolled out anyway.
+	ConditionUnknownConfigKeys = "UnknownConfigKeys"
+	// ConditionConfigApplied is true if all nodes applied the current reloadable config. It is
+	// unknown while there are no nodes or a node didn't report its config status yet.
+	ConditionConfigApplied = "ConfigApplied"
+	// ConditionBundleReady is true if the plugin bundle for the current external
+	// plugins was built successfully. Otherwise, the VerneMQ pods keep the last
+	// successfully built bundle.
+	ConditionBundleReady = "BundleReady"
+	// ConditionFederationConnected is true if all nodes are connected to all peers
+	// of the federation and no peer of the peers Secret is invalid
+	ConditionFederationConnected = "FederationConnected"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // VerneMQ is the Schema for the vernemqs API
 // +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
 type VerneMQ struct {
 	metav1.TypeMeta `json:",inline"`
 	// Standard object’s metadata. More info:
@@ -0,0 +1,44 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerneMQACLSpec defines the topics a user may publish and subscribe to, enforced by the vmq_acl plugin
+// +k8s:openapi-gen=true
+type VerneMQACLSpec struct {
+	// Username the rules apply to. Without a username the rules apply to all
+	// clients and the topics may contain %c for the client id, %u for the
+	// username and %m for the mountpoint.
+	Username string `json:"username,omitempty"`
+	// Mountpoint the rules apply to. Defaults to the default mountpoint.
+	Mountpoint string `json:"mountpoint,omitempty"`
+	// Publish are the topic patterns the user may publish to
+	Publish []string `json:"publish,omitempty"`
+	// Subscribe are the topic patterns the user may subscribe to
+	Subscribe []string `json:"subscribe,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQACL is the Schema for the vernemqacls API
+// +k8s:openapi-gen=true
+type VerneMQACL struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VerneMQACLSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQACLList contains a list of VerneMQACL
+type VerneMQACLList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerneMQACL `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerneMQACL{}, &VerneMQACLList{})
+}
@@ -0,0 +1,128 @@
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerneMQBridgeSpec defines an MQTT bridge from a VerneMQ cluster to a remote broker
+// +k8s:openapi-gen=true
+type VerneMQBridgeSpec struct {
+	// VerneMQ is the name of the VerneMQ object in the same namespace that runs the bridge
+	VerneMQ string `json:"vernemq"`
+	// Host of the remote broker
+	Host string `json:"host"`
+	// Port of the remote broker
+	Port int32 `json:"port"`
+	// TLS connects to the remote broker using TLS
+	TLS *BridgeTLS `json:"tls,omitempty"`
+	// CredentialsSecret references a Secret with the keys "username" and "password"
+	// used to connect to the remote broker
+	CredentialsSecret *v1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+	// ClientID of the bridge. Defaults to an id generated by vmq_bridge.
+	ClientID string `json:"clientID,omitempty"`
+	// CleanSession starts a clean session on every connect
+	CleanSession *bool `json:"cleanSession,omitempty"`
+	// KeepaliveInterval in seconds
+	KeepaliveInterval *int32 `json:"keepaliveInterval,omitempty"`
+	// RestartTimeout is the number of seconds to wait before reconnecting
+	RestartTimeout *int32 `json:"restartTimeout,omitempty"`
+	// RetryInterval is the number of seconds after which unacknowledged messages are resent
+	RetryInterval *int32 `json:"retryInterval,omitempty"`
+	// MaxOutgoingBufferedMessages while the remote broker isn't reachable
+	MaxOutgoingBufferedMessages *int32 `json:"maxOutgoingBufferedMessages,omitempty"`
+	// MQTTVersion used to connect to the remote broker
+	// +kubebuilder:validation:Enum=3;4
+	MQTTVersion *int32 `json:"mqttVersion,omitempty"`
+	// TryPrivate signals the remote broker that it is connected to a bridge
+	TryPrivate *bool `json:"tryPrivate,omitempty"`
+	// Topics are the topic mappings of the bridge
+	Topics []BridgeTopic `json:"topics"`
+}
+
+// BridgeTLS defines the TLS settings of a bridge
+// +k8s:openapi-gen=true
+type BridgeTLS struct {
+	// SecretName is the name of a Secret with the key "ca.crt" holding the CA
+	// certificates trusted by the bridge, and "tls.crt" and "tls.key" if
+	// ClientCertificate is set
+	SecretName string `json:"secretName"`
+	// ClientCertificate authenticates the bridge with the certificate of the Secret
+	ClientCertificate bool `json:"clientCertificate,omitempty"`
+	// Insecure skips the verification of the remote broker's certificate
+	Insecure bool `json:"insecure,omitempty"`
+	// TLSVersion used to connect, e.g. "tlsv1.2"
+	TLSVersion string `json:"tlsVersion,omitempty"`
+}
+
+// BridgeTopic maps topics between the local and the remote broker
+// +k8s:openapi-gen=true
+type BridgeTopic struct {
+	// Pattern is the topic pattern to bridge
+	Pattern string `json:"pattern"`
+	// Direction the messages are bridged in, "in" from the remote broker,
+	// "out" to the remote broker or "both"
+	// +kubebuilder:validation:Enum=in;out;both
+	Direction string `json:"direction"`
+	// QoS of the bridged messages
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2
+	QoS int32 `json:"qos,omitempty"`
+	// LocalPrefix is prepended to the topics on the local broker
+	LocalPrefix string `json:"localPrefix,omitempty"`
+	// RemotePrefix is prepended to the topics on the remote broker. vmq_bridge only
+	// accepts it together with LocalPrefix.
+	RemotePrefix string `json:"remotePrefix,omitempty"`
+}
+
+// VerneMQBridgeStatus defines the observed state of VerneMQBridge
+// +k8s:openapi-gen=true
+type VerneMQBridgeStatus struct {
+	// Nodes reports the connection state of the bridge on each VerneMQ node
+	Nodes []BridgeNodeStatus `json:"nodes,omitempty"`
+	// Conditions describe the current state of the bridge
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// BridgeNodeStatus defines the state of a bridge on a VerneMQ node
+// +k8s:openapi-gen=true
+type BridgeNodeStatus struct {
+	// Node is the name of the VerneMQ pod
+	Node string `json:"node"`
+	// State of the bridge connection as reported by vmq_bridge, e.g. "connected"
+	State string `json:"state,omitempty"`
+}
+
+const (
+	// ConditionBridgeAccepted is false if the bridge is invalid or its name in vernemq.conf
+	// conflicts with another bridge of the VerneMQ object. Such bridges are not rendered.
+	ConditionBridgeAccepted = "Accepted"
+	// ConditionBridgeConnected is true if the bridge is connected on all VerneMQ nodes
+	ConditionBridgeConnected = "Connected"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQBridge is the Schema for the vernemqbridges API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type VerneMQBridge struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VerneMQBridgeSpec   `json:"spec"`
+	Status VerneMQBridgeStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQBridgeList contains a list of VerneMQBridge
+type VerneMQBridgeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerneMQBridge `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerneMQBridge{}, &VerneMQBridgeList{})
+}
@@ -0,0 +1,42 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerneMQPluginSpec defines a plugin together with its source, config and lifecycle commands
+// +k8s:openapi-gen=true
+type VerneMQPluginSpec struct {
+	// Plugin defines the name of the plugin application, its path and the
+	// commands executed when it is enabled or disabled
+	Plugin `json:",inline"`
+	// Source fetches the plugin with the Plugin Bundler. Plugins shipped with
+	// VerneMQ don't need a source.
+	Source *PluginSourceSpec `json:"source,omitempty"`
+	// Configs are the reloadable config items of the plugin
+	Configs []ConfigItem `json:"configs,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQPlugin is the Schema for the vernemqplugins API
+// +k8s:openapi-gen=true
+type VerneMQPlugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VerneMQPluginSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQPluginList contains a list of VerneMQPlugin
+type VerneMQPluginList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerneMQPlugin `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerneMQPlugin{}, &VerneMQPluginList{})
+}
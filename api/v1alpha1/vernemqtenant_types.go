@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerneMQTenantSpec binds a mountpoint of a VerneMQ cluster to the listeners,
+// users and ACLs of a tenant. Tenants have no message rate limit, see the
+// Tenants section of the README for the limits VerneMQ supports instead.
+// +k8s:openapi-gen=true
+type VerneMQTenantSpec struct {
+	// VerneMQ is the name of the VerneMQ object in the same namespace that serves the tenant
+	VerneMQ string `json:"vernemq"`
+	// Mountpoint isolating the topics of the tenant
+	// +kubebuilder:validation:MinLength=1
+	Mountpoint string `json:"mountpoint"`
+	// Listeners are added to the reloadable config of the VerneMQ object with the
+	// mountpoint of the tenant. Their mountpoint must be empty or the mountpoint of
+	// the tenant, their port must not be used by another listener.
+	Listeners []Listener `json:"listeners,omitempty"`
+	// MaxConnections is the number of allowed concurrent connections of each listener
+	// of the tenant that doesn't set its own maxConnections
+	MaxConnections int `json:"maxConnections,omitempty"`
+	// UserSelector selects the VerneMQUsers of the tenant. The users are only
+	// enabled if the VerneMQ object sets spec.auth.userSelector.
+	UserSelector *metav1.LabelSelector `json:"userSelector,omitempty"`
+	// ACLSelector selects the VerneMQACLs of the tenant, which are applied to the
+	// mountpoint of the tenant regardless of their own mountpoint. The ACLs are only
+	// enabled if the VerneMQ object sets spec.auth.aclSelector.
+	ACLSelector *metav1.LabelSelector `json:"aclSelector,omitempty"`
+}
+
+// VerneMQTenantStatus defines the observed state of VerneMQTenant
+// +k8s:openapi-gen=true
+type VerneMQTenantStatus struct {
+	// Conditions describe the current state of the tenant
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ConditionTenantAccepted is false if the mountpoint or a listener port of the tenant
+// conflicts with the VerneMQ object or another tenant. Such tenants are not merged.
+const ConditionTenantAccepted = "Accepted"
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQTenant is the Schema for the vernemqtenants API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type VerneMQTenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VerneMQTenantSpec   `json:"spec"`
+	Status VerneMQTenantStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQTenantList contains a list of VerneMQTenant
+type VerneMQTenantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerneMQTenant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerneMQTenant{}, &VerneMQTenantList{})
+}
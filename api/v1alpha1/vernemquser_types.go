@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerneMQUserSpec defines a user authenticated by the vmq_passwd plugin
+// +k8s:openapi-gen=true
+type VerneMQUserSpec struct {
+	// Username of the user
+	Username string `json:"username"`
+	// PasswordSecretRef selects the key of a Secret in the namespace of the user holding the password
+	PasswordSecretRef v1.SecretKeySelector `json:"passwordSecretRef"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQUser is the Schema for the vernemqusers API
+// +k8s:openapi-gen=true
+type VerneMQUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VerneMQUserSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerneMQUserList contains a list of VerneMQUser
+type VerneMQUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerneMQUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerneMQUser{}, &VerneMQUserList{})
+}
@@ -25,199 +25,1243 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertsSpec) DeepCopyInto(out *AlertsSpec) {
+	*out = *in
+	if in.QueueGrowthRate != nil {
+		in, out := &in.QueueGrowthRate, &out.QueueGrowthRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertsSpec.
+func (in *AlertsSpec) DeepCopy() *AlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthSpec) DeepCopyInto(out *AuthSpec) {
+	*out = *in
+	if in.UserSelector != nil {
+		in, out := &in.UserSelector, &out.UserSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ACLSelector != nil {
+		in, out := &in.ACLSelector, &out.ACLSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diversity != nil {
+		in, out := &in.Diversity, &out.Diversity
+		*out = new(DiversitySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthSpec.
+func (in *AuthSpec) DeepCopy() *AuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BridgeNodeStatus) DeepCopyInto(out *BridgeNodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BridgeNodeStatus.
+func (in *BridgeNodeStatus) DeepCopy() *BridgeNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BridgeNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BridgeTLS) DeepCopyInto(out *BridgeTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BridgeTLS.
+func (in *BridgeTLS) DeepCopy() *BridgeTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(BridgeTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BridgeTopic) DeepCopyInto(out *BridgeTopic) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BridgeTopic.
+func (in *BridgeTopic) DeepCopy() *BridgeTopic {
+	if in == nil {
+		return nil
+	}
+	out := new(BridgeTopic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleStatus) DeepCopyInto(out *BundleStatus) {
+	*out = *in
+	if in.BuildTime != nil {
+		in, out := &in.BuildTime, &out.BuildTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]BundledPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleStatus.
+func (in *BundleStatus) DeepCopy() *BundleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundledPlugin) DeepCopyInto(out *BundledPlugin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundledPlugin.
+func (in *BundledPlugin) DeepCopy() *BundledPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(BundledPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundlerSpec) DeepCopyInto(out *BundlerSpec) {
+	*out = *in
+	if in.VmqK8s != nil {
+		in, out := &in.VmqK8s, &out.VmqK8s
+		*out = new(PluginSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(corev1.VolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundlerSpec.
+func (in *BundlerSpec) DeepCopy() *BundlerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BundlerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Command) DeepCopyInto(out *Command) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Command.
-func (in *Command) DeepCopy() *Command {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Command.
+func (in *Command) DeepCopy() *Command {
+	if in == nil {
+		return nil
+	}
+	out := new(Command)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigApplyFailure) DeepCopyInto(out *ConfigApplyFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigApplyFailure.
+func (in *ConfigApplyFailure) DeepCopy() *ConfigApplyFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigApplyFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigItem) DeepCopyInto(out *ConfigItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigItem.
+func (in *ConfigItem) DeepCopy() *ConfigItem {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapSource) DeepCopyInto(out *ConfigMapSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapSource.
+func (in *ConfigMapSource) DeepCopy() *ConfigMapSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiversitySpec) DeepCopyInto(out *DiversitySpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PoolSize != nil {
+		in, out := &in.PoolSize, &out.PoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SSL != nil {
+		in, out := &in.SSL, &out.SSL
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Scripts != nil {
+		in, out := &in.Scripts, &out.Scripts
+		*out = new(LuaScripts)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiversitySpec.
+func (in *DiversitySpec) DeepCopy() *DiversitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiversitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ErlangVMConfig) DeepCopyInto(out *ErlangVMConfig) {
+	*out = *in
+	if in.ProcessLimit != nil {
+		in, out := &in.ProcessLimit, &out.ProcessLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AsyncThreads != nil {
+		in, out := &in.AsyncThreads, &out.AsyncThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Schedulers != nil {
+		in, out := &in.Schedulers, &out.Schedulers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SchedulersOnline != nil {
+		in, out := &in.SchedulersOnline, &out.SchedulersOnline
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DistributionBufferSize != nil {
+		in, out := &in.DistributionBufferSize, &out.DistributionBufferSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KernelPoll != nil {
+		in, out := &in.KernelPoll, &out.KernelPoll
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExtraFlags != nil {
+		in, out := &in.ExtraFlags, &out.ExtraFlags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErlangVMConfig.
+func (in *ErlangVMConfig) DeepCopy() *ErlangVMConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ErlangVMConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationEndpoint) DeepCopyInto(out *FederationEndpoint) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationEndpoint.
+func (in *FederationEndpoint) DeepCopy() *FederationEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationPeerStatus) DeepCopyInto(out *FederationPeerStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]BridgeNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationPeerStatus.
+func (in *FederationPeerStatus) DeepCopy() *FederationPeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationPeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationSpec) DeepCopyInto(out *FederationSpec) {
+	*out = *in
+	in.Advertise.DeepCopyInto(&out.Advertise)
+	out.PeersSecret = in.PeersSecret
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(BridgeTLS)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]BridgeTopic, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationSpec.
+func (in *FederationSpec) DeepCopy() *FederationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HexSource) DeepCopyInto(out *HexSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HexSource.
+func (in *HexSource) DeepCopy() *HexSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HexSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LevelDBConfig) DeepCopyInto(out *LevelDBConfig) {
+	*out = *in
+	if in.MaximumMemoryPercent != nil {
+		in, out := &in.MaximumMemoryPercent, &out.MaximumMemoryPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WriteBufferSizeMin != nil {
+		in, out := &in.WriteBufferSizeMin, &out.WriteBufferSizeMin
+		*out = new(int64)
+		**out = **in
+	}
+	if in.WriteBufferSizeMax != nil {
+		in, out := &in.WriteBufferSizeMax, &out.WriteBufferSizeMax
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LevelDBConfig.
+func (in *LevelDBConfig) DeepCopy() *LevelDBConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LevelDBConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Listener) DeepCopyInto(out *Listener) {
+	*out = *in
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(TLSConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Listener.
+func (in *Listener) DeepCopy() *Listener {
+	if in == nil {
+		return nil
+	}
+	out := new(Listener)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingConfig) DeepCopyInto(out *LoggingConfig) {
+	*out = *in
+	if in.CrashLog != nil {
+		in, out := &in.CrashLog, &out.CrashLog
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingConfig.
+func (in *LoggingConfig) DeepCopy() *LoggingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LuaScripts) DeepCopyInto(out *LuaScripts) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LuaScripts.
+func (in *LuaScripts) DeepCopy() *LuaScripts {
+	if in == nil {
+		return nil
+	}
+	out := new(LuaScripts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(AlertsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConfigStatus) DeepCopyInto(out *NodeConfigStatus) {
+	*out = *in
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]ConfigApplyFailure, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConfigStatus.
+func (in *NodeConfigStatus) DeepCopy() *NodeConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plugin) DeepCopyInto(out *Plugin) {
+	*out = *in
+	if in.PreStart != nil {
+		in, out := &in.PreStart, &out.PreStart
+		*out = make([]Command, len(*in))
+		copy(*out, *in)
+	}
+	if in.PostStart != nil {
+		in, out := &in.PostStart, &out.PostStart
+		*out = make([]Command, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreStop != nil {
+		in, out := &in.PreStop, &out.PreStop
+		*out = make([]Command, len(*in))
+		copy(*out, *in)
+	}
+	if in.PostStop != nil {
+		in, out := &in.PostStop, &out.PostStop
+		*out = make([]Command, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plugin.
+func (in *Plugin) DeepCopy() *Plugin {
+	if in == nil {
+		return nil
+	}
+	out := new(Plugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginBundleSpec) DeepCopyInto(out *PluginBundleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginBundleSpec.
+func (in *PluginBundleSpec) DeepCopy() *PluginBundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginBundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginSource) DeepCopyInto(out *PluginSource) {
+	*out = *in
+	in.PluginSourceSpec.DeepCopyInto(&out.PluginSourceSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSource.
+func (in *PluginSource) DeepCopy() *PluginSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginSourceSpec) DeepCopyInto(out *PluginSourceSpec) {
+	*out = *in
+	if in.Hex != nil {
+		in, out := &in.Hex, &out.Hex
+		*out = new(HexSource)
+		**out = **in
+	}
+	if in.Tarball != nil {
+		in, out := &in.Tarball, &out.Tarball
+		*out = new(TarballSource)
+		**out = **in
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapSource)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSourceSpec.
+func (in *PluginSourceSpec) DeepCopy() *PluginSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSettings) DeepCopyInto(out *ProbeSettings) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSettings.
+func (in *ProbeSettings) DeepCopy() *ProbeSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbesSpec) DeepCopyInto(out *ProbesSpec) {
+	*out = *in
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(ProbeSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(ProbeSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(ProbeSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesSpec.
+func (in *ProbesSpec) DeepCopy() *ProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReloadableConfig) DeepCopyInto(out *ReloadableConfig) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]Plugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]Listener, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Configs != nil {
+		in, out := &in.Configs, &out.Configs
+		*out = make([]ConfigItem, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReloadableConfig.
+func (in *ReloadableConfig) DeepCopy() *ReloadableConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReloadableConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticConfig) DeepCopyInto(out *StaticConfig) {
+	*out = *in
+	if in.AllowAnonymous != nil {
+		in, out := &in.AllowAnonymous, &out.AllowAnonymous
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxInflightMessages != nil {
+		in, out := &in.MaxInflightMessages, &out.MaxInflightMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOnlineMessages != nil {
+		in, out := &in.MaxOnlineMessages, &out.MaxOnlineMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOfflineMessages != nil {
+		in, out := &in.MaxOfflineMessages, &out.MaxOfflineMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LevelDB != nil {
+		in, out := &in.LevelDB, &out.LevelDB
+		*out = new(LevelDBConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(LoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticConfig.
+func (in *StaticConfig) DeepCopy() *StaticConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
+	*out = *in
+	if in.EmptyDir != nil {
+		in, out := &in.EmptyDir, &out.EmptyDir
+		*out = new(corev1.EmptyDirVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	in.VolumeClaimTemplate.DeepCopyInto(&out.VolumeClaimTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
+func (in *StorageSpec) DeepCopy() *StorageSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Command)
+	out := new(StorageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ConfigItem) DeepCopyInto(out *ConfigItem) {
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigItem.
-func (in *ConfigItem) DeepCopy() *ConfigItem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ConfigItem)
+	out := new(TLSConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Listener) DeepCopyInto(out *Listener) {
+func (in *TarballSource) DeepCopyInto(out *TarballSource) {
 	*out = *in
-	if in.TLSConfig != nil {
-		in, out := &in.TLSConfig, &out.TLSConfig
-		*out = new(TLSConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TarballSource.
+func (in *TarballSource) DeepCopy() *TarballSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TarballSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpec) DeepCopyInto(out *TopologySpec) {
+	*out = *in
+	if in.MaxSkew != nil {
+		in, out := &in.MaxSkew, &out.MaxSkew
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Listener.
-func (in *Listener) DeepCopy() *Listener {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpec.
+func (in *TopologySpec) DeepCopy() *TopologySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Listener)
+	out := new(TopologySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Plugin) DeepCopyInto(out *Plugin) {
+func (in *VerneMQ) DeepCopyInto(out *VerneMQ) {
 	*out = *in
-	if in.PreStart != nil {
-		in, out := &in.PreStart, &out.PreStart
-		*out = make([]Command, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQ.
+func (in *VerneMQ) DeepCopy() *VerneMQ {
+	if in == nil {
+		return nil
 	}
-	if in.PostStart != nil {
-		in, out := &in.PostStart, &out.PostStart
-		*out = make([]Command, len(*in))
-		copy(*out, *in)
+	out := new(VerneMQ)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQ) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	if in.PreStop != nil {
-		in, out := &in.PreStop, &out.PreStop
-		*out = make([]Command, len(*in))
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQACL) DeepCopyInto(out *VerneMQACL) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQACL.
+func (in *VerneMQACL) DeepCopy() *VerneMQACL {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQACL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQACL) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQACLList) DeepCopyInto(out *VerneMQACLList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VerneMQACL, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQACLList.
+func (in *VerneMQACLList) DeepCopy() *VerneMQACLList {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQACLList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQACLList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQACLSpec) DeepCopyInto(out *VerneMQACLSpec) {
+	*out = *in
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.PostStop != nil {
-		in, out := &in.PostStop, &out.PostStop
-		*out = make([]Command, len(*in))
+	if in.Subscribe != nil {
+		in, out := &in.Subscribe, &out.Subscribe
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plugin.
-func (in *Plugin) DeepCopy() *Plugin {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQACLSpec.
+func (in *VerneMQACLSpec) DeepCopy() *VerneMQACLSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Plugin)
+	out := new(VerneMQACLSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PluginSource) DeepCopyInto(out *PluginSource) {
+func (in *VerneMQBridge) DeepCopyInto(out *VerneMQBridge) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSource.
-func (in *PluginSource) DeepCopy() *PluginSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQBridge.
+func (in *VerneMQBridge) DeepCopy() *VerneMQBridge {
 	if in == nil {
 		return nil
 	}
-	out := new(PluginSource)
+	out := new(VerneMQBridge)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQBridge) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReloadableConfig) DeepCopyInto(out *ReloadableConfig) {
+func (in *VerneMQBridgeList) DeepCopyInto(out *VerneMQBridgeList) {
 	*out = *in
-	if in.Plugins != nil {
-		in, out := &in.Plugins, &out.Plugins
-		*out = make([]Plugin, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VerneMQBridge, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Listeners != nil {
-		in, out := &in.Listeners, &out.Listeners
-		*out = make([]Listener, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQBridgeList.
+func (in *VerneMQBridgeList) DeepCopy() *VerneMQBridgeList {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQBridgeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQBridgeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQBridgeSpec) DeepCopyInto(out *VerneMQBridgeSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(BridgeTLS)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CleanSession != nil {
+		in, out := &in.CleanSession, &out.CleanSession
+		*out = new(bool)
+		**out = **in
+	}
+	if in.KeepaliveInterval != nil {
+		in, out := &in.KeepaliveInterval, &out.KeepaliveInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RestartTimeout != nil {
+		in, out := &in.RestartTimeout, &out.RestartTimeout
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOutgoingBufferedMessages != nil {
+		in, out := &in.MaxOutgoingBufferedMessages, &out.MaxOutgoingBufferedMessages
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MQTTVersion != nil {
+		in, out := &in.MQTTVersion, &out.MQTTVersion
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TryPrivate != nil {
+		in, out := &in.TryPrivate, &out.TryPrivate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]BridgeTopic, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQBridgeSpec.
+func (in *VerneMQBridgeSpec) DeepCopy() *VerneMQBridgeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQBridgeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQBridgeStatus) DeepCopyInto(out *VerneMQBridgeStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]BridgeNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Configs != nil {
-		in, out := &in.Configs, &out.Configs
-		*out = make([]ConfigItem, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReloadableConfig.
-func (in *ReloadableConfig) DeepCopy() *ReloadableConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQBridgeStatus.
+func (in *VerneMQBridgeStatus) DeepCopy() *VerneMQBridgeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ReloadableConfig)
+	out := new(VerneMQBridgeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
+func (in *VerneMQList) DeepCopyInto(out *VerneMQList) {
 	*out = *in
-	if in.EmptyDir != nil {
-		in, out := &in.EmptyDir, &out.EmptyDir
-		*out = new(corev1.EmptyDirVolumeSource)
-		(*in).DeepCopyInto(*out)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VerneMQ, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	in.VolumeClaimTemplate.DeepCopyInto(&out.VolumeClaimTemplate)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
-func (in *StorageSpec) DeepCopy() *StorageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQList.
+func (in *VerneMQList) DeepCopy() *VerneMQList {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageSpec)
+	out := new(VerneMQList)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
-func (in *TLSConfig) DeepCopy() *TLSConfig {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(TLSConfig)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VerneMQ) DeepCopyInto(out *VerneMQ) {
+func (in *VerneMQPlugin) DeepCopyInto(out *VerneMQPlugin) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQ.
-func (in *VerneMQ) DeepCopy() *VerneMQ {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQPlugin.
+func (in *VerneMQPlugin) DeepCopy() *VerneMQPlugin {
 	if in == nil {
 		return nil
 	}
-	out := new(VerneMQ)
+	out := new(VerneMQPlugin)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VerneMQ) DeepCopyObject() runtime.Object {
+func (in *VerneMQPlugin) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -225,37 +1269,63 @@ func (in *VerneMQ) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VerneMQList) DeepCopyInto(out *VerneMQList) {
+func (in *VerneMQPluginList) DeepCopyInto(out *VerneMQPluginList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VerneMQ, len(*in))
+		*out = make([]VerneMQPlugin, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQList.
-func (in *VerneMQList) DeepCopy() *VerneMQList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQPluginList.
+func (in *VerneMQPluginList) DeepCopy() *VerneMQPluginList {
 	if in == nil {
 		return nil
 	}
-	out := new(VerneMQList)
+	out := new(VerneMQPluginList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VerneMQList) DeepCopyObject() runtime.Object {
+func (in *VerneMQPluginList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQPluginSpec) DeepCopyInto(out *VerneMQPluginSpec) {
+	*out = *in
+	in.Plugin.DeepCopyInto(&out.Plugin)
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(PluginSourceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Configs != nil {
+		in, out := &in.Configs, &out.Configs
+		*out = make([]ConfigItem, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQPluginSpec.
+func (in *VerneMQPluginSpec) DeepCopy() *VerneMQPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VerneMQSpec) DeepCopyInto(out *VerneMQSpec) {
 	*out = *in
@@ -336,6 +1406,16 @@ func (in *VerneMQSpec) DeepCopyInto(out *VerneMQSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.StaticConfig != nil {
+		in, out := &in.StaticConfig, &out.StaticConfig
+		*out = new(StaticConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ErlangVM != nil {
+		in, out := &in.ErlangVM, &out.ErlangVM
+		*out = new(ErlangVMConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]corev1.EnvVar, len(*in))
@@ -348,12 +1428,66 @@ func (in *VerneMQSpec) DeepCopyInto(out *VerneMQSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Bundler != nil {
+		in, out := &in.Bundler, &out.Bundler
+		*out = new(BundlerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PluginBundle != nil {
+		in, out := &in.PluginBundle, &out.PluginBundle
+		*out = new(PluginBundleSpec)
+		**out = **in
+	}
+	if in.PluginSelector != nil {
+		in, out := &in.PluginSelector, &out.PluginSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ExternalPlugins != nil {
 		in, out := &in.ExternalPlugins, &out.ExternalPlugins
 		*out = make([]PluginSource, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Config.DeepCopyInto(&out.Config)
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(AuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]Webhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(FederationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(TopologySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQSpec.
@@ -374,6 +1508,37 @@ func (in *VerneMQStatus) DeepCopyInto(out *VerneMQStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClusterView != nil {
+		in, out := &in.ClusterView, &out.ClusterView
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigStatus != nil {
+		in, out := &in.ConfigStatus, &out.ConfigStatus
+		*out = make([]NodeConfigStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bundle != nil {
+		in, out := &in.Bundle, &out.Bundle
+		*out = new(BundleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = make([]FederationPeerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQStatus.
@@ -385,3 +1550,215 @@ func (in *VerneMQStatus) DeepCopy() *VerneMQStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQTenant) DeepCopyInto(out *VerneMQTenant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQTenant.
+func (in *VerneMQTenant) DeepCopy() *VerneMQTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQTenant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQTenantList) DeepCopyInto(out *VerneMQTenantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VerneMQTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQTenantList.
+func (in *VerneMQTenantList) DeepCopy() *VerneMQTenantList {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQTenantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQTenantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQTenantSpec) DeepCopyInto(out *VerneMQTenantSpec) {
+	*out = *in
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]Listener, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UserSelector != nil {
+		in, out := &in.UserSelector, &out.UserSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ACLSelector != nil {
+		in, out := &in.ACLSelector, &out.ACLSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQTenantSpec.
+func (in *VerneMQTenantSpec) DeepCopy() *VerneMQTenantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQTenantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQTenantStatus) DeepCopyInto(out *VerneMQTenantStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQTenantStatus.
+func (in *VerneMQTenantStatus) DeepCopy() *VerneMQTenantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQTenantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQUser) DeepCopyInto(out *VerneMQUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQUser.
+func (in *VerneMQUser) DeepCopy() *VerneMQUser {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQUserList) DeepCopyInto(out *VerneMQUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VerneMQUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQUserList.
+func (in *VerneMQUserList) DeepCopy() *VerneMQUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerneMQUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerneMQUserSpec) DeepCopyInto(out *VerneMQUserSpec) {
+	*out = *in
+	in.PasswordSecretRef.DeepCopyInto(&out.PasswordSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerneMQUserSpec.
+func (in *VerneMQUserSpec) DeepCopy() *VerneMQUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VerneMQUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Webhook) DeepCopyInto(out *Webhook) {
+	*out = *in
+	if in.Base64Payload != nil {
+		in, out := &in.Base64Payload, &out.Base64Payload
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BasicAuthSecretRef != nil {
+		in, out := &in.BasicAuthSecretRef, &out.BasicAuthSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Webhook.
+func (in *Webhook) DeepCopy() *Webhook {
+	if in == nil {
+		return nil
+	}
+	out := new(Webhook)
+	in.DeepCopyInto(out)
+	return out
+}
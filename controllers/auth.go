@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	authDir        = "/vernemq/etc/auth"
+	authVolumeName = "vernemq-auth"
+	passwdFileKey  = "vmq.passwd"
+	aclFileKey     = "vmq.acl"
+
+	// authReloadInterval is the interval in seconds vmq_passwd and vmq_acl
+	// check the mounted files for changes
+	authReloadInterval = "10"
+)
+
+func authSecretName(name string) string {
+	return fmt.Sprintf("%s-auth", prefixedName(name))
+}
+
+// authConfEntries enables the authentication plugins configured by the instance
+func authConfEntries(instance *vernemqv1alpha1.VerneMQ) []confEntry {
+	auth := instance.Spec.Auth
+	if auth == nil {
+		auth = &vernemqv1alpha1.AuthSpec{}
+	}
+	var entries []confEntry
+	if auth.UserSelector != nil {
+		entries = append(entries,
+			confEntry{"plugins.vmq_passwd", "on"},
+			confEntry{"vmq_passwd.password_file", fmt.Sprintf("%s/%s", authDir, passwdFileKey)},
+			confEntry{"vmq_passwd.password_reload_interval", authReloadInterval},
+		)
+	} else {
+		entries = append(entries, confEntry{"plugins.vmq_passwd", "off"})
+	}
+	if auth.ACLSelector != nil {
+		entries = append(entries,
+			confEntry{"plugins.vmq_acl", "on"},
+			confEntry{"vmq_acl.acl_file", fmt.Sprintf("%s/%s", authDir, aclFileKey)},
+			confEntry{"vmq_acl.acl_reload_interval", authReloadInterval},
+		)
+	} else {
+		entries = append(entries, confEntry{"plugins.vmq_acl", "off"})
+	}
+	if auth.Diversity != nil {
+		entries = append(entries, diversityConfEntries(auth.Diversity)...)
+	}
+	return entries
+}
+
+// makeAuthSecret compiles the VerneMQUsers and VerneMQACLs selected by the instance
+// and its tenants into the password and ACL files. Password hashes of the existing
+// Secret are kept as long as they match the password, so that the files only
+// change with the users.
+func (r *ReconcileVerneMQ) makeAuthSecret(ctx context.Context, instance *vernemqv1alpha1.VerneMQ, tenants []vernemqv1alpha1.VerneMQTenant) (*v1.Secret, error) {
+	users := &vernemqv1alpha1.VerneMQUserList{}
+	if err := r.listSelected(ctx, instance.Namespace, instance.Spec.Auth.UserSelector, users); err != nil {
+		return nil, pkgerr.Wrap(err, "list users")
+	}
+	acls := &vernemqv1alpha1.VerneMQACLList{}
+	if err := r.listSelected(ctx, instance.Namespace, instance.Spec.Auth.ACLSelector, acls); err != nil {
+		return nil, pkgerr.Wrap(err, "list ACLs")
+	}
+	tenantUsers, tenantACLs, err := r.tenantAuth(ctx, instance, tenants)
+	if err != nil {
+		return nil, err
+	}
+	acls.Items = append(acls.Items, tenantACLs...)
+	// users selected by the instance and a tenant are only added once
+	selectedUsers := map[string]bool{}
+	for _, user := range users.Items {
+		selectedUsers[user.Name] = true
+	}
+	for _, user := range tenantUsers {
+		if !selectedUsers[user.Name] {
+			selectedUsers[user.Name] = true
+			users.Items = append(users.Items, user)
+		}
+	}
+
+	existing := &v1.Secret{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: authSecretName(instance.Name), Namespace: instance.Namespace}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, pkgerr.Wrap(err, "get auth secret")
+	}
+	hashes := parsePasswd(string(existing.Data[passwdFileKey]))
+
+	passwords := map[string]string{}
+	for _, user := range users.Items {
+		if _, ok := passwords[user.Spec.Username]; ok {
+			return nil, pkgerr.Errorf("user %s is defined more than once", user.Spec.Username)
+		}
+		ref := user.Spec.PasswordSecretRef
+		secret := &v1.Secret{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: instance.Namespace}, secret)
+		if err != nil {
+			return nil, pkgerr.Wrapf(err, "get password of user %s", user.Spec.Username)
+		}
+		password, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, pkgerr.Errorf("password secret %s of user %s has no key %s", ref.Name, user.Spec.Username, ref.Key)
+		}
+		passwords[user.Spec.Username] = string(password)
+	}
+	passwd, err := renderPasswd(passwords, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	boolTrue := true
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      authSecretName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		Data: map[string][]byte{
+			passwdFileKey: []byte(passwd),
+			aclFileKey:    []byte(renderACL(acls.Items)),
+		},
+	}, nil
+}
+
+// renderPasswd renders the vmq_passwd file, reusing the hashes that still match the passwords
+func renderPasswd(passwords map[string]string, hashes map[string]string) (string, error) {
+	var b strings.Builder
+	for _, username := range sortedKeys(passwords) {
+		hash, ok := hashes[username]
+		if !ok || !verifyPassword(hash, passwords[username]) {
+			salt := make([]byte, 12)
+			if _, err := rand.Read(salt); err != nil {
+				return "", pkgerr.Wrap(err, "generate salt")
+			}
+			hash = hashPassword(passwords[username], salt)
+		}
+		fmt.Fprintf(&b, "%s:%s\n", username, hash)
+	}
+	return b.String(), nil
+}
+
+// parsePasswd returns the password hashes of a vmq_passwd file by username
+func parsePasswd(passwd string) map[string]string {
+	hashes := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(passwd))
+	for scanner.Scan() {
+		if i := strings.LastIndex(scanner.Text(), ":"); i > 0 {
+			hashes[scanner.Text()[:i]] = scanner.Text()[i+1:]
+		}
+	}
+	return hashes
+}
+
+// hashPassword hashes the password in the `$6$salt$hash` format of vmq_passwd,
+// where hash is the SHA-512 of the password followed by the salt
+func hashPassword(password string, salt []byte) string {
+	hash := sha512.Sum512(append([]byte(password), salt...))
+	return fmt.Sprintf("$6$%s$%s", base64.StdEncoding.EncodeToString(salt), base64.StdEncoding.EncodeToString(hash[:]))
+}
+
+func verifyPassword(hash string, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[1] != "6" {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashPassword(password, salt)), []byte(hash)) == 1
+}
+
+// renderACL renders the vmq_acl file. The rules are grouped by mountpoint, rules
+// without a username are rendered as patterns applying to all clients.
+func renderACL(acls []vernemqv1alpha1.VerneMQACL) string {
+	type rules struct {
+		patterns []string
+		users    map[string][]string
+	}
+	mountpoints := map[string]*rules{}
+	for _, acl := range acls {
+		mp, ok := mountpoints[acl.Spec.Mountpoint]
+		if !ok {
+			mp = &rules{users: map[string][]string{}}
+			mountpoints[acl.Spec.Mountpoint] = mp
+		}
+		var lines []string
+		for _, t := range acl.Spec.Subscribe {
+			lines = append(lines, "read "+t)
+		}
+		for _, t := range acl.Spec.Publish {
+			lines = append(lines, "write "+t)
+		}
+		if acl.Spec.Username == "" {
+			mp.patterns = append(mp.patterns, lines...)
+		} else {
+			mp.users[acl.Spec.Username] = append(mp.users[acl.Spec.Username], lines...)
+		}
+	}
+
+	var b strings.Builder
+	for _, name := range sortedKeys(mountpoints) {
+		mp := mountpoints[name]
+		if name != "" {
+			fmt.Fprintf(&b, "mountpoint %s\n", name)
+		}
+		sort.Strings(mp.patterns)
+		for _, p := range mp.patterns {
+			fmt.Fprintf(&b, "pattern %s\n", p)
+		}
+		for _, username := range sortedKeys(mp.users) {
+			fmt.Fprintf(&b, "user %s\n", username)
+			topics := mp.users[username]
+			sort.Strings(topics)
+			for _, t := range topics {
+				fmt.Fprintf(&b, "topic %s\n", t)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash := hashPassword("secret", []byte("salt"))
+	want := "$6$c2FsdA==$E491yrR9AdCoE7rbOPYS3EZgSuZpVE65AD9xko08s6floNesY/Zpe9zMVvLix4S2FiQSJ99RIkNvhHomNO9uLw=="
+	if hash != want {
+		t.Fatalf("hashPassword() = %q, want %q", hash, want)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"matching password", hash, "secret", true},
+		{"other password", hash, "other", false},
+		{"other salt", hashPassword("secret", []byte("pepper")), "secret", true},
+		{"other scheme", "$5$c2FsdA==$abc", "secret", false},
+		{"invalid salt", "$6$!!!$abc", "secret", false},
+		{"plain text", "secret", "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("verifyPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderACL(t *testing.T) {
+	acl := func(username string, mountpoint string, publish []string, subscribe []string) vernemqv1alpha1.VerneMQACL {
+		return vernemqv1alpha1.VerneMQACL{Spec: vernemqv1alpha1.VerneMQACLSpec{
+			Username:   username,
+			Mountpoint: mountpoint,
+			Publish:    publish,
+			Subscribe:  subscribe,
+		}}
+	}
+	tests := []struct {
+		name string
+		acls []vernemqv1alpha1.VerneMQACL
+		want string
+	}{
+		{
+			name: "no ACLs",
+			want: "",
+		},
+		{
+			name: "pattern",
+			acls: []vernemqv1alpha1.VerneMQACL{acl("", "", []string{"devices/%c/out"}, []string{"devices/%c/in"})},
+			want: "pattern read devices/%c/in\npattern write devices/%c/out\n",
+		},
+		{
+			name: "users are merged and sorted",
+			acls: []vernemqv1alpha1.VerneMQACL{
+				acl("bob", "", []string{"b"}, nil),
+				acl("alice", "", nil, []string{"a"}),
+				acl("bob", "", nil, []string{"a"}),
+			},
+			want: "user alice\ntopic read a\nuser bob\ntopic read a\ntopic write b\n",
+		},
+		{
+			name: "mountpoints",
+			acls: []vernemqv1alpha1.VerneMQACL{
+				acl("alice", "tenant", []string{"t"}, nil),
+				acl("", "", nil, []string{"#"}),
+			},
+			want: "pattern read #\nmountpoint tenant\nuser alice\ntopic write t\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderACL(tt.acls); got != tt.want {
+				t.Errorf("renderACL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
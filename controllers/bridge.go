@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var invalidConfNameRegexp = regexp.MustCompile(`[^a-z0-9_]`)
+
+// bridgeConfName returns the name of the bridge in vernemq.conf
+func bridgeConfName(name string) string {
+	return invalidConfNameRegexp.ReplaceAllString(strings.ToLower(name), "_")
+}
+
+// selectedBridges lists the VerneMQBridges referencing the instance, ordered by name
+func (r *ReconcileVerneMQ) selectedBridges(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) ([]vernemqv1alpha1.VerneMQBridge, error) {
+	list := &vernemqv1alpha1.VerneMQBridgeList{}
+	if err := r.client.List(ctx, list, client.InNamespace(instance.Namespace)); err != nil {
+		return nil, pkgerr.Wrap(err, "list bridges")
+	}
+	var bridges []vernemqv1alpha1.VerneMQBridge
+	for _, b := range list.Items {
+		if b.Spec.VerneMQ == instance.Name {
+			bridges = append(bridges, b)
+		}
+	}
+	sort.Slice(bridges, func(i, j int) bool { return bridges[i].Name < bridges[j].Name })
+	return bridges, nil
+}
+
+// validateBridge checks the topics of a bridge, which vmq_bridge only parses when
+// the bridge is started
+func validateBridge(b vernemqv1alpha1.VerneMQBridge) error {
+	for i, t := range b.Spec.Topics {
+		if t.RemotePrefix != "" && t.LocalPrefix == "" {
+			return fmt.Errorf("topic %d sets remotePrefix without localPrefix", i+1)
+		}
+	}
+	return nil
+}
+
+// acceptBridges returns the bridges that can be rendered along with the reserved
+// bridges, ordered by name, and the reason each other bridge is skipped for by its
+// name. Of bridges with the same name in vernemq.conf the oldest one is accepted.
+func acceptBridges(bridges []vernemqv1alpha1.VerneMQBridge, reserved []vernemqv1alpha1.VerneMQBridge) ([]vernemqv1alpha1.VerneMQBridge, map[string]string) {
+	owners := map[string]string{}
+	for _, b := range reserved {
+		owners[bridgeConfName(b.Name)] = b.Name
+	}
+	byAge := append([]vernemqv1alpha1.VerneMQBridge{}, bridges...)
+	sort.SliceStable(byAge, func(i, j int) bool {
+		if !byAge[i].CreationTimestamp.Equal(&byAge[j].CreationTimestamp) {
+			return byAge[i].CreationTimestamp.Before(&byAge[j].CreationTimestamp)
+		}
+		return byAge[i].Name < byAge[j].Name
+	})
+	rejected := map[string]string{}
+	for _, b := range byAge {
+		if err := validateBridge(b); err != nil {
+			rejected[b.Name] = err.Error()
+			continue
+		}
+		name := bridgeConfName(b.Name)
+		if owner, ok := owners[name]; ok {
+			rejected[b.Name] = fmt.Sprintf("the name %s in vernemq.conf is already used by bridge %s", name, owner)
+			continue
+		}
+		owners[name] = b.Name
+	}
+	var accepted []vernemqv1alpha1.VerneMQBridge
+	for _, b := range bridges {
+		if _, ok := rejected[b.Name]; !ok {
+			accepted = append(accepted, b)
+		}
+	}
+	return accepted, rejected
+}
+
+// mergeBridges renders the bridges into the VMQConfig of the instance. The TLS
+// and credentials Secrets are added to the mounted Secrets, so that changing a
+// bridge rolls the VerneMQ pods like any other static config.
+func mergeBridges(instance *vernemqv1alpha1.VerneMQ, bridges []vernemqv1alpha1.VerneMQBridge) {
+	if len(bridges) == 0 {
+		return
+	}
+	var entries []confEntry
+	if !hasConfKey(instance.Spec.VMQConfig, "plugins.vmq_bridge") {
+		entries = append(entries, confEntry{"plugins.vmq_bridge", "on"})
+	}
+	for _, b := range bridges {
+		entries = append(entries, bridgeConfEntries(b)...)
+		if b.Spec.CredentialsSecret != nil && !containsString(instance.Spec.Secrets, b.Spec.CredentialsSecret.Name) {
+			instance.Spec.Secrets = append(instance.Spec.Secrets, b.Spec.CredentialsSecret.Name)
+		}
+		if b.Spec.TLS != nil && !containsString(instance.Spec.Secrets, b.Spec.TLS.SecretName) {
+			instance.Spec.Secrets = append(instance.Spec.Secrets, b.Spec.TLS.SecretName)
+		}
+	}
+	instance.Spec.VMQConfig = strings.TrimRight(instance.Spec.VMQConfig, "\n") + "\n" + renderConfEntries(entries)
+}
+
+func bridgeConfEntries(b vernemqv1alpha1.VerneMQBridge) []confEntry {
+	transport := "tcp"
+	if b.Spec.TLS != nil {
+		transport = "ssl"
+	}
+	prefix := fmt.Sprintf("vmq_bridge.%s.%s", transport, bridgeConfName(b.Name))
+	entries := []confEntry{{prefix, fmt.Sprintf("%s:%d", b.Spec.Host, b.Spec.Port)}}
+	add := func(key string, value string) {
+		entries = append(entries, confEntry{prefix + "." + key, value})
+	}
+	if b.Spec.ClientID != "" {
+		add("client_id", b.Spec.ClientID)
+	}
+	if b.Spec.CleanSession != nil {
+		add("cleansession", onOff(*b.Spec.CleanSession))
+	}
+	if b.Spec.KeepaliveInterval != nil {
+		add("keepalive_interval", fmt.Sprint(*b.Spec.KeepaliveInterval))
+	}
+	if b.Spec.RestartTimeout != nil {
+		add("restart_timeout", fmt.Sprint(*b.Spec.RestartTimeout))
+	}
+	if b.Spec.RetryInterval != nil {
+		add("retry_interval", fmt.Sprint(*b.Spec.RetryInterval))
+	}
+	if b.Spec.MaxOutgoingBufferedMessages != nil {
+		add("max_outgoing_buffered_messages", fmt.Sprint(*b.Spec.MaxOutgoingBufferedMessages))
+	}
+	if b.Spec.MQTTVersion != nil {
+		add("mqtt_version", fmt.Sprint(*b.Spec.MQTTVersion))
+	}
+	if b.Spec.TryPrivate != nil {
+		add("try_private", onOff(*b.Spec.TryPrivate))
+	}
+	if b.Spec.CredentialsSecret != nil {
+		add("username", secretFileValue(b.Spec.CredentialsSecret.Name, "username"))
+		add("password", secretFileValue(b.Spec.CredentialsSecret.Name, "password"))
+	}
+	if tls := b.Spec.TLS; tls != nil {
+		dir := secretsDir + tls.SecretName
+		add("cafile", dir+"/ca.crt")
+		if tls.ClientCertificate {
+			add("certfile", dir+"/tls.crt")
+			add("keyfile", dir+"/tls.key")
+		}
+		if tls.Insecure {
+			add("insecure", "on")
+		}
+		if tls.TLSVersion != "" {
+			add("tls_version", tls.TLSVersion)
+		}
+	}
+	for i, t := range b.Spec.Topics {
+		topic := fmt.Sprintf("%s %s %d", t.Pattern, t.Direction, t.QoS)
+		if t.LocalPrefix != "" || t.RemotePrefix != "" {
+			topic = fmt.Sprintf("%s %s %s", topic, t.LocalPrefix, t.RemotePrefix)
+		}
+		add(fmt.Sprintf("topic.%d", i+1), strings.TrimSpace(topic))
+	}
+	return entries
+}
+
+func secretEnvVar(name string, secret v1.LocalObjectReference, key string) v1.EnvVar {
+	return v1.EnvVar{
+		Name: name,
+		ValueFrom: &v1.EnvVarSource{
+			SecretKeyRef: &v1.SecretKeySelector{
+				LocalObjectReference: secret,
+				Key:                  key,
+			},
+		},
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeBridgeReport is the part of the config status report of a node listing
+// the state of each bridge by its name in vernemq.conf
+type nodeBridgeReport struct {
+	Bridges map[string]string `json:"bridges"`
+}
+
+// nodeBridgeReports parses the bridge states reported by the pods, pods without
+// a valid report are included without bridges
+func nodeBridgeReports(configStatus *v1.ConfigMap, pods []v1.Pod) map[string]nodeBridgeReport {
+	reports := map[string]nodeBridgeReport{}
+	for _, pod := range pods {
+		report := nodeBridgeReport{}
+		if data, ok := configStatus.Data[pod.Name]; ok {
+			// invalid reports are reported in the status of the VerneMQ object
+			_ = json.Unmarshal([]byte(data), &report)
+		}
+		reports[pod.Name] = report
+	}
+	return reports
+}
+
+// bridgeNodeStates returns the state of the bridge on each node, ordered by node,
+// and the nodes on which it isn't connected
+func bridgeNodeStates(reports map[string]nodeBridgeReport, name string) ([]vernemqv1alpha1.BridgeNodeStatus, []string) {
+	var nodes []vernemqv1alpha1.BridgeNodeStatus
+	var disconnected []string
+	for _, node := range sortedKeys(reports) {
+		state := reports[node].Bridges[bridgeConfName(name)]
+		nodes = append(nodes, vernemqv1alpha1.BridgeNodeStatus{Node: node, State: state})
+		if state != "connected" {
+			disconnected = append(disconnected, node)
+		}
+	}
+	return nodes, disconnected
+}
+
+// setBridgeAccepted records in the status of the bridge whether it is rendered
+func setBridgeAccepted(bridge *vernemqv1alpha1.VerneMQBridge, reason string) {
+	condition := metav1.Condition{
+		Type:               vernemqv1alpha1.ConditionBridgeAccepted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: bridge.Generation,
+		Reason:             "Accepted",
+	}
+	if reason != "" {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Rejected"
+		condition.Message = reason
+	}
+	meta.SetStatusCondition(&bridge.Status.Conditions, condition)
+}
+
+// updateBridgeStatus records whether the bridges are accepted and the bridge states
+// reported by the nodes in the status of the bridges
+func (r *ReconcileVerneMQ) updateBridgeStatus(ctx context.Context, bridges []vernemqv1alpha1.VerneMQBridge, rejected map[string]string, configStatus *v1.ConfigMap, pods []v1.Pod) error {
+	reports := nodeBridgeReports(configStatus, pods)
+	for i := range bridges {
+		bridge := &bridges[i]
+		observed := bridge.Status.DeepCopy()
+		reason, isRejected := rejected[bridge.Name]
+		setBridgeAccepted(bridge, reason)
+		if isRejected {
+			bridge.Status.Nodes = nil
+			meta.RemoveStatusCondition(&bridge.Status.Conditions, vernemqv1alpha1.ConditionBridgeConnected)
+		} else {
+			nodes, disconnected := bridgeNodeStates(reports, bridge.Name)
+			bridge.Status.Nodes = nodes
+			condition := metav1.Condition{
+				Type:               vernemqv1alpha1.ConditionBridgeConnected,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: bridge.Generation,
+				Reason:             "Connected",
+			}
+			if len(disconnected) > 0 {
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = "Disconnected"
+				condition.Message = fmt.Sprintf("the bridge isn't connected on nodes %v", disconnected)
+			}
+			meta.SetStatusCondition(&bridge.Status.Conditions, condition)
+		}
+		if reflect.DeepEqual(*observed, bridge.Status) {
+			continue
+		}
+		if err := r.client.Status().Update(ctx, bridge); err != nil {
+			return pkgerr.Wrapf(err, "update status of bridge %s", bridge.Name)
+		}
+	}
+	return nil
+}
+
+// verneMQOfBridge maps a VerneMQBridge to the VerneMQ object it references
+func verneMQOfBridge(obj client.Object) []reconcile.Request {
+	bridge, ok := obj.(*vernemqv1alpha1.VerneMQBridge)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: bridge.Spec.VerneMQ, Namespace: bridge.Namespace}},
+	}
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAcceptBridges(t *testing.T) {
+	topics := []vernemqv1alpha1.BridgeTopic{{Pattern: "#", Direction: "out"}}
+	bridge := func(name string, age time.Duration, topics []vernemqv1alpha1.BridgeTopic) vernemqv1alpha1.VerneMQBridge {
+		return vernemqv1alpha1.VerneMQBridge{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(time.Unix(0, 0).Add(-age))},
+			Spec:       vernemqv1alpha1.VerneMQBridgeSpec{Topics: topics},
+		}
+	}
+	tests := []struct {
+		name         string
+		bridges      []vernemqv1alpha1.VerneMQBridge
+		reserved     []vernemqv1alpha1.VerneMQBridge
+		wantAccepted []string
+		wantRejected []string
+	}{
+		{
+			name:         "valid bridges",
+			bridges:      []vernemqv1alpha1.VerneMQBridge{bridge("a", 0, topics), bridge("b", 0, topics)},
+			wantAccepted: []string{"a", "b"},
+		},
+		{
+			name: "remote prefix without local prefix",
+			bridges: []vernemqv1alpha1.VerneMQBridge{
+				bridge("a", 0, []vernemqv1alpha1.BridgeTopic{{Pattern: "#", Direction: "out", RemotePrefix: "remote/"}}),
+				bridge("b", 0, topics),
+			},
+			wantAccepted: []string{"b"},
+			wantRejected: []string{"a"},
+		},
+		{
+			name:         "the oldest bridge keeps a shared name",
+			bridges:      []vernemqv1alpha1.VerneMQBridge{bridge("a-b", 0, topics), bridge("a_b", time.Hour, topics)},
+			wantAccepted: []string{"a_b"},
+			wantRejected: []string{"a-b"},
+		},
+		{
+			name:         "names of federation peers are reserved",
+			bridges:      []vernemqv1alpha1.VerneMQBridge{bridge("federation-east", time.Hour, topics)},
+			reserved:     []vernemqv1alpha1.VerneMQBridge{bridge("federation_east", 0, topics)},
+			wantRejected: []string{"federation-east"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, rejected := acceptBridges(tt.bridges, tt.reserved)
+			var acceptedNames, rejectedNames []string
+			for _, b := range accepted {
+				acceptedNames = append(acceptedNames, b.Name)
+			}
+			for _, name := range sortedKeys(rejected) {
+				rejectedNames = append(rejectedNames, name)
+			}
+			if !reflect.DeepEqual(acceptedNames, tt.wantAccepted) {
+				t.Errorf("acceptBridges() accepted = %v, want %v", acceptedNames, tt.wantAccepted)
+			}
+			if !reflect.DeepEqual(rejectedNames, tt.wantRejected) {
+				t.Errorf("acceptBridges() rejected = %v, want %v", rejectedNames, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestMergeBridges(t *testing.T) {
+	bridges := []vernemqv1alpha1.VerneMQBridge{{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud"},
+		Spec:       vernemqv1alpha1.VerneMQBridgeSpec{Host: "broker", Port: 1883, Topics: []vernemqv1alpha1.BridgeTopic{{Pattern: "#", Direction: "out"}}},
+	}}
+	for _, vmqConfig := range []string{"", "plugins.vmq_bridge = on"} {
+		instance := &vernemqv1alpha1.VerneMQ{Spec: vernemqv1alpha1.VerneMQSpec{VMQConfig: vmqConfig}}
+		mergeBridges(instance, bridges)
+		if n := strings.Count(instance.Spec.VMQConfig, "plugins.vmq_bridge"); n != 1 {
+			t.Errorf("mergeBridges() with vmqConfig %q enables vmq_bridge %d times", vmqConfig, n)
+		}
+		if _, err := makeVerneMQConf(instance); err != nil {
+			t.Errorf("makeVerneMQConf() error = %v", err)
+		}
+	}
+}
+
+func TestBridgeConfEntries(t *testing.T) {
+	cleanSession := false
+	keepalive := int32(30)
+	tests := []struct {
+		name   string
+		bridge vernemqv1alpha1.VerneMQBridge
+		want   []confEntry
+	}{
+		{
+			name: "tcp",
+			bridge: vernemqv1alpha1.VerneMQBridge{
+				ObjectMeta: metav1.ObjectMeta{Name: "Cloud-1"},
+				Spec: vernemqv1alpha1.VerneMQBridgeSpec{
+					Host:              "broker",
+					Port:              1883,
+					ClientID:          "edge",
+					CleanSession:      &cleanSession,
+					KeepaliveInterval: &keepalive,
+					Topics: []vernemqv1alpha1.BridgeTopic{
+						{Pattern: "#", Direction: "out", QoS: 1},
+						{Pattern: "cmd/#", Direction: "in", LocalPrefix: "local/", RemotePrefix: "remote/"},
+					},
+				},
+			},
+			want: []confEntry{
+				{"vmq_bridge.tcp.cloud_1", "broker:1883"},
+				{"vmq_bridge.tcp.cloud_1.client_id", "edge"},
+				{"vmq_bridge.tcp.cloud_1.cleansession", "off"},
+				{"vmq_bridge.tcp.cloud_1.keepalive_interval", "30"},
+				{"vmq_bridge.tcp.cloud_1.topic.1", "# out 1"},
+				{"vmq_bridge.tcp.cloud_1.topic.2", "cmd/# in 0 local/ remote/"},
+			},
+		},
+		{
+			name: "ssl with credentials",
+			bridge: vernemqv1alpha1.VerneMQBridge{
+				ObjectMeta: metav1.ObjectMeta{Name: "cloud"},
+				Spec: vernemqv1alpha1.VerneMQBridgeSpec{
+					Host:              "broker",
+					Port:              8883,
+					CredentialsSecret: &v1.LocalObjectReference{Name: "cloud-auth"},
+					TLS:               &vernemqv1alpha1.BridgeTLS{SecretName: "cloud-tls", ClientCertificate: true, TLSVersion: "tlsv1.2"},
+					Topics:            []vernemqv1alpha1.BridgeTopic{{Pattern: "#", Direction: "both", QoS: 2, LocalPrefix: "cloud/"}},
+				},
+			},
+			want: []confEntry{
+				{"vmq_bridge.ssl.cloud", "broker:8883"},
+				{"vmq_bridge.ssl.cloud.username", "@file:/vernemq/etc/secrets/cloud-auth/username"},
+				{"vmq_bridge.ssl.cloud.password", "@file:/vernemq/etc/secrets/cloud-auth/password"},
+				{"vmq_bridge.ssl.cloud.cafile", "/vernemq/etc/secrets/cloud-tls/ca.crt"},
+				{"vmq_bridge.ssl.cloud.certfile", "/vernemq/etc/secrets/cloud-tls/tls.crt"},
+				{"vmq_bridge.ssl.cloud.keyfile", "/vernemq/etc/secrets/cloud-tls/tls.key"},
+				{"vmq_bridge.ssl.cloud.tls_version", "tlsv1.2"},
+				{"vmq_bridge.ssl.cloud.topic.1", "# both 2 cloud/"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bridgeConfEntries(tt.bridge); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bridgeConfEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
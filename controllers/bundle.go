@@ -0,0 +1,437 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	pluginsDir        = "/vernemq/plugins"
+	pluginsVolumeName = "vernemq-plugins"
+	bundleCacheDir    = storageDir + "/.plugin-bundle"
+
+	defaultPluginBundlePath = "/plugins"
+
+	bundlerLockDir        = "/vmq-bundler/lock"
+	bundlerLockVolumeName = "vmq-bundler-lock"
+)
+
+// fetchPluginsCommand downloads the plugin bundle from the bundler, verifies it
+// against the published digest and caches it on the data volume. If the bundler
+// can't be reached, the last cached bundle is used if it matches the digest.
+// Bundlers without /status never publish a digest, their bundles are used
+// without verification.
+var fetchPluginsCommand = []string{"/bin/sh", "-c", fmt.Sprintf(`
+	cache=%[1]s
+	mkdir -p $cache
+	if [ -z "$VMQ_BUNDLE_DIGEST" ]; then
+		echo "no plugin bundle digest published, the plugin bundle isn't verified"
+	fi
+	verify() {
+		[ -z "$VMQ_BUNDLE_DIGEST" ] || [ "$(sha256sum "$1" | cut -d' ' -f1)" = "$VMQ_BUNDLE_DIGEST" ]
+	}
+	attempt=1
+	delay=2
+	while :; do
+		if curl -fsSL --connect-timeout 5 -o /tmp/bundle.tar.gz http://$VMQ_BUNDLER_HOST/bundle.tar.gz; then
+			if verify /tmp/bundle.tar.gz; then
+				mv /tmp/bundle.tar.gz $cache/bundle.tar.gz
+				break
+			fi
+			echo "plugin bundle doesn't match digest $VMQ_BUNDLE_DIGEST"
+		fi
+		if [ $attempt -ge 6 ]; then
+			echo "fetching plugin bundle failed"
+			break
+		fi
+		echo "fetching plugin bundle failed, retrying in ${delay}s"
+		sleep $delay
+		attempt=$((attempt + 1))
+		delay=$((delay * 2))
+	done
+	if [ ! -f $cache/bundle.tar.gz ]; then
+		echo "no plugin bundle available"
+		exit 1
+	fi
+	if ! verify $cache/bundle.tar.gz; then
+		echo "cached plugin bundle doesn't match digest $VMQ_BUNDLE_DIGEST"
+		exit 1
+	fi
+	tar xzf $cache/bundle.tar.gz -C %[2]s`, bundleCacheDir, pluginsDir)}
+
+// makeCopyPluginsContainer returns the init container that copies the plugins
+// of a prebuilt bundle image to the VerneMQ container
+func makeCopyPluginsContainer(bundle *vernemqv1alpha1.PluginBundleSpec, securityContext *v1.SecurityContext) v1.Container {
+	path := bundle.Path
+	if path == "" {
+		path = defaultPluginBundlePath
+	}
+	return v1.Container{
+		Name:            "copy-plugins",
+		Image:           bundle.Image,
+		ImagePullPolicy: bundle.ImagePullPolicy,
+		Command:         []string{"/bin/sh", "-c", fmt.Sprintf("mkdir -p %[2]s && cp -a %[1]s/. %[2]s", shellQuote(path), pluginsDir+"/_build/default")},
+		SecurityContext: securityContext,
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      pluginsVolumeName,
+				MountPath: pluginsDir,
+			},
+		},
+	}
+}
+
+// deleteBundler removes the Plugin Bundler and its ConfigMaps of instances switched
+// to a prebuilt bundle
+func (r *ReconcileVerneMQ) deleteBundler(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) error {
+	objects := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: deploymentName(instance.Name), Namespace: instance.Namespace}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: bundlerServiceName(instance.Name), Namespace: instance.Namespace}},
+		&policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: bundlerPDBName(instance.Name), Namespace: instance.Namespace}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: bundlerGitConfigMapName(instance.Name), Namespace: instance.Namespace}},
+		// the rebar.lock recorded by earlier releases
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: prefixedName(instance.Name) + "-bundler-lock", Namespace: instance.Namespace}},
+	}
+	for _, obj := range objects {
+		if err := r.client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return pkgerr.Wrapf(err, "delete %s", obj.GetName())
+		}
+	}
+	return nil
+}
+
+// makeFetchPluginsContainer returns the init container that provides the plugin
+// bundle to the VerneMQ container
+func makeFetchPluginsContainer(instance *vernemqv1alpha1.VerneMQ, image string, dataMount v1.VolumeMount, securityContext *v1.SecurityContext) v1.Container {
+	return v1.Container{
+		Name:            "fetch-plugins",
+		Image:           image,
+		Command:         fetchPluginsCommand,
+		SecurityContext: securityContext,
+		VolumeMounts: []v1.VolumeMount{
+			dataMount,
+			{
+				Name:      pluginsVolumeName,
+				MountPath: pluginsDir,
+			},
+		},
+		Env: []v1.EnvVar{
+			{
+				Name:  "VMQ_BUNDLER_HOST",
+				Value: bundlerServiceName(instance.Name),
+			},
+			{
+				Name: "VMQ_BUNDLE_DIGEST",
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{
+						FieldPath: fmt.Sprintf("metadata.annotations['%s']", sSetBundleDigestName),
+					},
+				},
+			},
+		},
+	}
+}
+
+func bundlerLockConfigMapName(name string) string {
+	return fmt.Sprintf("%s-bundler-lock", prefixedName(name))
+}
+
+// makeBundlerLockConfigMap returns the ConfigMap recording the rebar.lock of the
+// last successful build together with the hash of the BUNDLER_CONFIG it resolves
+func makeBundlerLockConfigMap(instance *vernemqv1alpha1.VerneMQ, lock string) *v1.ConfigMap {
+	boolTrue := true
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bundlerLockConfigMapName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		Data: map[string]string{
+			"configHash": configHash(makeBundlerConfig(instance)),
+			"rebar.lock": lock,
+		},
+	}
+}
+
+// makeBundlerLockVolume returns the volume providing the recorded rebar.lock to
+// the bundler. The bundler only uses it if its configHash matches the hash of its
+// BUNDLER_CONFIG, so that rebuilds of an unchanged config resolve the same commits.
+func makeBundlerLockVolume(instance *vernemqv1alpha1.VerneMQ) (v1.Volume, v1.VolumeMount, v1.EnvVar) {
+	optional := true
+	volume := v1.Volume{
+		Name: bundlerLockVolumeName,
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: bundlerLockConfigMapName(instance.Name)},
+				Optional:             &optional,
+			},
+		},
+	}
+	mount := v1.VolumeMount{
+		Name:      bundlerLockVolumeName,
+		MountPath: bundlerLockDir,
+		ReadOnly:  true,
+	}
+	env := v1.EnvVar{
+		Name:  "BUNDLER_LOCK_DIR",
+		Value: bundlerLockDir,
+	}
+	return volume, mount, env
+}
+
+// bundlerStatus is the build state reported by the bundler on /status, see
+// docs/bundler-api.md
+type bundlerStatus struct {
+	// State is one of "building", "succeeded" or "failed"
+	State string `json:"state"`
+	// ConfigHash is the SHA-256 hash of the BUNDLER_CONFIG the state refers to
+	ConfigHash string `json:"configHash"`
+	// Digest is the SHA-256 digest of the bundle.tar.gz served by the bundler
+	Digest string `json:"digest"`
+	// Finished is the RFC 3339 time the last build finished
+	Finished string `json:"finished"`
+	// DurationSeconds is the duration of the last build
+	DurationSeconds float64 `json:"durationSeconds"`
+	Plugins         []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Commit  string `json:"commit"`
+	} `json:"plugins"`
+	Errors []string `json:"errors"`
+}
+
+// fetchBundlerStatus polls the build state of every running bundler pod of the
+// instance and combines them with mergeBundlerStatuses. The pods are polled
+// directly, the Service only reaches the pods that serve a bundle.
+func (r *ReconcileVerneMQ) fetchBundlerStatus(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) (*bundlerStatus, error) {
+	pods := &v1.PodList{}
+	err := r.client.List(ctx, pods, client.InNamespace(instance.Namespace), client.MatchingLabels(bundlerPodLabels(instance.Name)))
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "list bundler pods")
+	}
+	var statuses []*bundlerStatus
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		status, err := r.fetchPodBundlerStatus(ctx, pod.Status.PodIP)
+		if err != nil {
+			return nil, pkgerr.Wrapf(err, "bundler pod %s", pod.Name)
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		return nil, pkgerr.New("no bundler pod is running")
+	}
+	return mergeBundlerStatuses(statuses, configHash(makeBundlerConfig(instance))), nil
+}
+
+// fetchPodBundlerStatus polls the build state of a single bundler pod
+func (r *ReconcileVerneMQ) fetchPodBundlerStatus(ctx context.Context, podIP string) (*bundlerStatus, error) {
+	url := fmt.Sprintf("http://%s/status", net.JoinHostPort(podIP, strconv.Itoa(bundlerHTTPPort)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "create bundler status request")
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "fetch bundler status")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, pkgerr.Errorf("fetch bundler status: unexpected status %s", resp.Status)
+	}
+	status := &bundlerStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, pkgerr.Wrap(err, "decode bundler status")
+	}
+	return status, nil
+}
+
+// mergeBundlerStatuses combines the build states of the bundler replicas. The
+// bundle is only built once every replica built the current config, and the
+// replicas must have built identical bundles, since the VerneMQ pods fetch it
+// from any of them.
+func mergeBundlerStatuses(statuses []*bundlerStatus, currentHash string) *bundlerStatus {
+	for _, status := range statuses {
+		if status.ConfigHash != currentHash {
+			return status
+		}
+	}
+	for _, status := range statuses {
+		if status.State == "failed" {
+			return status
+		}
+	}
+	for _, status := range statuses {
+		if status.State != "succeeded" {
+			return status
+		}
+	}
+	for _, status := range statuses[1:] {
+		if status.Digest != statuses[0].Digest {
+			return &bundlerStatus{
+				State:      "failed",
+				ConfigHash: currentHash,
+				Finished:   status.Finished,
+				Errors: []string{fmt.Sprintf("the bundler replicas built bundles with different digests %s and %s, builds must be reproducible",
+					statuses[0].Digest, status.Digest)},
+			}
+		}
+	}
+	return statuses[0]
+}
+
+// fetchBundlerLock fetches the rebar.lock of the last successful build. The
+// replicas built identical bundles, so any ready bundler pod serves the lock.
+func (r *ReconcileVerneMQ) fetchBundlerLock(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) (string, error) {
+	url := fmt.Sprintf("http://%s.%s.svc/rebar.lock", bundlerServiceName(instance.Name), instance.Namespace)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", pkgerr.Wrap(err, "create bundler lock request")
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", pkgerr.Wrap(err, "fetch bundler lock")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", pkgerr.Errorf("fetch bundler lock: unexpected status %s", resp.Status)
+	}
+	lock, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", pkgerr.Wrap(err, "read bundler lock")
+	}
+	return string(lock), nil
+}
+
+// recordBundlerLock stores the rebar.lock of a successful build of the current
+// config. A lock already recorded for the config is kept, so it isn't replaced
+// by builds that didn't use it.
+func (r *ReconcileVerneMQ) recordBundlerLock(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) error {
+	if instance.Status.Bundle == nil || instance.Status.Bundle.BuildState != vernemqv1alpha1.BundleSucceeded {
+		return nil
+	}
+	existing := &v1.ConfigMap{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: bundlerLockConfigMapName(instance.Name), Namespace: instance.Namespace}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return pkgerr.Wrap(err, "get bundler lock")
+	}
+	if err == nil && existing.Data["configHash"] == configHash(makeBundlerConfig(instance)) {
+		return nil
+	}
+	lock, err := r.fetchBundlerLock(ctx, instance)
+	if err != nil {
+		return err
+	}
+	lockConfigMap := makeBundlerLockConfigMap(instance, lock)
+	return r.createOrUpdate(ctx, instance, lockConfigMap.Name, lockConfigMap.Namespace, lockConfigMap)
+}
+
+// pluginsHash identifies the plugins provided to the VerneMQ pods
+func pluginsHash(instance *vernemqv1alpha1.VerneMQ) string {
+	if instance.Spec.PluginBundle != nil {
+		return configHash(instance.Spec.PluginBundle.Image)
+	}
+	return configHash(makeBundlerConfig(instance))
+}
+
+// updateBundleStatus records the build state reported by the bundler. The digest
+// and plugins are only updated by successful builds of the current config, so that
+// VerneMQ pods keep verifying against the last good bundle.
+func updateBundleStatus(instance *vernemqv1alpha1.VerneMQ, status *bundlerStatus) {
+	if instance.Status.Bundle == nil {
+		instance.Status.Bundle = &vernemqv1alpha1.BundleStatus{}
+	}
+	bundle := instance.Status.Bundle
+	if status.ConfigHash != configHash(makeBundlerConfig(instance)) {
+		// the bundler hasn't picked up the current plugins yet
+		bundle.BuildState = vernemqv1alpha1.BundleBuilding
+		return
+	}
+	if finished, err := time.Parse(time.RFC3339, status.Finished); err == nil {
+		buildTime := metav1.NewTime(finished)
+		bundle.BuildTime = &buildTime
+	}
+	switch status.State {
+	case "succeeded":
+		bundle.BuildState = vernemqv1alpha1.BundleSucceeded
+		bundle.Digest = status.Digest
+		bundle.Errors = nil
+		bundle.Plugins = nil
+		for _, p := range status.Plugins {
+			bundle.Plugins = append(bundle.Plugins, vernemqv1alpha1.BundledPlugin{
+				Name:    p.Name,
+				Version: p.Version,
+				Commit:  p.Commit,
+			})
+		}
+	case "failed":
+		bundle.BuildState = vernemqv1alpha1.BundleFailed
+		bundle.Errors = status.Errors
+	default:
+		bundle.BuildState = vernemqv1alpha1.BundleBuilding
+	}
+}
+
+// bundleReadyCondition reports whether new VerneMQ pods may be rolled out
+func bundleReadyCondition(instance *vernemqv1alpha1.VerneMQ) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               vernemqv1alpha1.ConditionBundleReady,
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: instance.Generation,
+		Reason:             "Unknown",
+		Message:            "the bundler didn't report a build yet",
+	}
+	if instance.Spec.PluginBundle != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PrebuiltBundle"
+		condition.Message = ""
+		return condition
+	}
+	if instance.Status.Bundle == nil {
+		return condition
+	}
+	switch instance.Status.Bundle.BuildState {
+	case vernemqv1alpha1.BundleSucceeded:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "BuildSucceeded"
+		condition.Message = ""
+	case vernemqv1alpha1.BundleFailed:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "BuildFailed"
+		condition.Message = strings.Join(instance.Status.Bundle.Errors, "\n")
+	case vernemqv1alpha1.BundleBuilding:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Building"
+		condition.Message = "waiting for the bundler to build the current plugins"
+	}
+	return condition
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeleteBundler(t *testing.T) {
+	instance := &vernemqv1alpha1.VerneMQ{ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "messaging"}}
+	names := []string{
+		bundlerGitConfigMapName(instance.Name),
+		bundlerLockConfigMapName(instance.Name),
+	}
+	builder := fake.NewClientBuilder()
+	for _, name := range names {
+		builder = builder.WithObjects(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: instance.Namespace}})
+	}
+	r := &ReconcileVerneMQ{client: builder.Build()}
+
+	// the Deployment, Service and PodDisruptionBudget don't exist
+	if err := r.deleteBundler(context.Background(), instance); err != nil {
+		t.Fatalf("deleteBundler() error = %v", err)
+	}
+	for _, name := range names {
+		err := r.client.Get(context.Background(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, &v1.ConfigMap{})
+		if !errors.IsNotFound(err) {
+			t.Errorf("ConfigMap %s still exists, error = %v", name, err)
+		}
+	}
+}
+
+func TestBundleDigestAnnotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		bundle         *vernemqv1alpha1.BundleStatus
+		pluginBundle   *vernemqv1alpha1.PluginBundleSpec
+		wantDigest     string
+		wantPluginHash bool
+	}{
+		{
+			name:       "built bundle",
+			bundle:     &vernemqv1alpha1.BundleStatus{Digest: "abc", BuildState: vernemqv1alpha1.BundleBuilding},
+			wantDigest: "abc",
+		},
+		{
+			name:           "no digest published",
+			wantPluginHash: true,
+		},
+		{
+			name:         "prebuilt bundle",
+			pluginBundle: &vernemqv1alpha1.PluginBundleSpec{Image: "registry.example.com/plugins:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "messaging"},
+				Spec:       vernemqv1alpha1.VerneMQSpec{PluginBundle: tt.pluginBundle},
+				Status:     vernemqv1alpha1.VerneMQStatus{Bundle: tt.bundle},
+			}
+			statefulset, err := makeStatefulSet(instance, "hash")
+			if err != nil {
+				t.Fatalf("makeStatefulSet() error = %v", err)
+			}
+			annotations := statefulset.Spec.Template.Annotations
+			if got := annotations[sSetBundleDigestName]; got != tt.wantDigest {
+				t.Errorf("bundle digest annotation = %q, want %q", got, tt.wantDigest)
+			}
+			if _, ok := annotations[sSetPluginsHashName]; ok != tt.wantPluginHash {
+				t.Errorf("plugins hash annotation set = %v, want %v", ok, tt.wantPluginHash)
+			}
+			if _, ok := statefulset.Annotations[sSetPluginsHashName]; ok {
+				t.Errorf("plugins hash annotation set on the StatefulSet")
+			}
+		})
+	}
+}
+
+func TestMergeBundlerStatuses(t *testing.T) {
+	succeeded := func(digest string) *bundlerStatus {
+		return &bundlerStatus{State: "succeeded", ConfigHash: "current", Digest: digest}
+	}
+	tests := []struct {
+		name           string
+		statuses       []*bundlerStatus
+		wantState      string
+		wantConfigHash string
+		wantDigest     string
+	}{
+		{
+			name:       "single replica",
+			statuses:   []*bundlerStatus{succeeded("a")},
+			wantState:  "succeeded",
+			wantDigest: "a",
+		},
+		{
+			name:       "replicas agree",
+			statuses:   []*bundlerStatus{succeeded("a"), succeeded("a")},
+			wantState:  "succeeded",
+			wantDigest: "a",
+		},
+		{
+			name:           "replica on the previous config",
+			statuses:       []*bundlerStatus{succeeded("a"), {State: "succeeded", ConfigHash: "previous", Digest: "b"}},
+			wantState:      "succeeded",
+			wantConfigHash: "previous",
+		},
+		{
+			name:      "replica still building",
+			statuses:  []*bundlerStatus{succeeded("a"), {State: "building", ConfigHash: "current"}},
+			wantState: "building",
+		},
+		{
+			name:      "replica failed",
+			statuses:  []*bundlerStatus{{State: "building", ConfigHash: "current"}, {State: "failed", ConfigHash: "current"}},
+			wantState: "failed",
+		},
+		{
+			name:      "builds not reproducible",
+			statuses:  []*bundlerStatus{succeeded("a"), succeeded("b")},
+			wantState: "failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeBundlerStatuses(tt.statuses, "current")
+			if got.State != tt.wantState {
+				t.Errorf("mergeBundlerStatuses() state = %q, want %q", got.State, tt.wantState)
+			}
+			wantConfigHash := tt.wantConfigHash
+			if wantConfigHash == "" {
+				wantConfigHash = "current"
+			}
+			if got.ConfigHash != wantConfigHash {
+				t.Errorf("mergeBundlerStatuses() configHash = %q, want %q", got.ConfigHash, wantConfigHash)
+			}
+			if tt.wantDigest != "" && got.Digest != tt.wantDigest {
+				t.Errorf("mergeBundlerStatuses() digest = %q, want %q", got.Digest, tt.wantDigest)
+			}
+		})
+	}
+}
+
+// roundTripFunc serves the requests of the operator to the bundler in tests
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecordBundlerLock(t *testing.T) {
+	instance := &vernemqv1alpha1.VerneMQ{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "messaging"},
+		Status: vernemqv1alpha1.VerneMQStatus{
+			Bundle: &vernemqv1alpha1.BundleStatus{BuildState: vernemqv1alpha1.BundleSucceeded},
+		},
+	}
+	tests := []struct {
+		name     string
+		existing *v1.ConfigMap
+		wantLock string
+	}{
+		{
+			name:     "no lock recorded",
+			wantLock: "fetched",
+		},
+		{
+			name:     "lock of the current config kept",
+			existing: makeBundlerLockConfigMap(instance, "recorded"),
+			wantLock: "recorded",
+		},
+		{
+			name: "lock of a previous config replaced",
+			existing: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: bundlerLockConfigMapName(instance.Name), Namespace: instance.Namespace},
+				Data:       map[string]string{"configHash": "previous", "rebar.lock": "recorded"},
+			},
+			wantLock: "fetched",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder()
+			if tt.existing != nil {
+				builder = builder.WithObjects(tt.existing)
+			}
+			r := &ReconcileVerneMQ{
+				client:   builder.Build(),
+				scheme:   scheme.Scheme,
+				logger:   logr.Discard(),
+				recorder: record.NewFakeRecorder(10),
+				httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != "/rebar.lock" {
+						t.Fatalf("unexpected request %s", req.URL)
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("fetched"))}, nil
+				})},
+			}
+			if err := r.recordBundlerLock(context.Background(), instance); err != nil {
+				t.Fatalf("recordBundlerLock() error = %v", err)
+			}
+			lock := &v1.ConfigMap{}
+			err := r.client.Get(context.Background(), types.NamespacedName{Name: bundlerLockConfigMapName(instance.Name), Namespace: instance.Namespace}, lock)
+			if err != nil {
+				t.Fatalf("get bundler lock: %v", err)
+			}
+			if got := lock.Data["rebar.lock"]; got != tt.wantLock {
+				t.Errorf("rebar.lock = %q, want %q", got, tt.wantLock)
+			}
+			if got := lock.Data["configHash"]; got != configHash(makeBundlerConfig(instance)) {
+				t.Errorf("configHash = %q, want the hash of the current config", got)
+			}
+		})
+	}
+}
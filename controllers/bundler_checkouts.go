@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	bundlerCheckoutsDir        = "/vmq-bundler/checkouts"
+	bundlerCheckoutsVolumeName = "vmq-bundler-checkouts"
+	bundlerSourcesDir          = "/vmq-bundler/sources"
+)
+
+// extractSourceFunc extracts a tarball into a plugin dir, dropping the top-level
+// dir of tarballs that contain a single one, as GitHub release archives do
+const extractSourceFunc = `extract() {
+	rm -rf /tmp/source && mkdir -p /tmp/source
+	tar xzf "$1" -C /tmp/source
+	set -- "$2" /tmp/source/*
+	if [ $# -eq 2 ] && [ -d "$2" ]; then mv "$2" "$1"; else mv /tmp/source "$1"; fi
+}
+`
+
+func sourceVolumeName(applicationName string) string {
+	return fmt.Sprintf("plugin-source-%s", strings.ReplaceAll(applicationName, "_", "-"))
+}
+
+// shellQuote quotes a string for use as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// resolveConfigMapSources sets the digest of the plugins built from ConfigMaps,
+// so that changing their content changes the BUNDLER_CONFIG. Missing ConfigMaps
+// have no digest, the bundler fails to extract them.
+func (r *ReconcileVerneMQ) resolveConfigMapSources(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) error {
+	sources := []*vernemqv1alpha1.PluginSourceSpec{}
+	for i := range instance.Spec.ExternalPlugins {
+		sources = append(sources, &instance.Spec.ExternalPlugins[i].PluginSourceSpec)
+	}
+	if instance.Spec.Bundler != nil && instance.Spec.Bundler.VmqK8s != nil {
+		sources = append(sources, &instance.Spec.Bundler.VmqK8s.PluginSourceSpec)
+	}
+	for _, source := range sources {
+		if source.ConfigMap == nil {
+			continue
+		}
+		configMap := &v1.ConfigMap{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: source.ConfigMap.Name, Namespace: instance.Namespace}, configMap)
+		if errors.IsNotFound(err) {
+			source.ConfigMap.Digest = ""
+			continue
+		}
+		if err != nil {
+			return pkgerr.Wrapf(err, "reading configmap %s failed", source.ConfigMap.Name)
+		}
+		sum := sha256.Sum256(configMap.BinaryData[source.ConfigMap.Key])
+		source.ConfigMap.Digest = hex.EncodeToString(sum[:])
+	}
+	return nil
+}
+
+// makeBundlerCheckouts returns the init container, volumes and environment that
+// provide the plugins from tarballs and ConfigMaps to rebar3 as checkout deps
+func makeBundlerCheckouts(instance *vernemqv1alpha1.VerneMQ, image string) (*v1.Container, []v1.Volume, []v1.VolumeMount, []v1.EnvVar) {
+	var script strings.Builder
+	var volumes []v1.Volume
+	mounts := []v1.VolumeMount{
+		{
+			Name:      bundlerCheckoutsVolumeName,
+			MountPath: bundlerCheckoutsDir,
+		},
+	}
+	for _, p := range bundledPlugins(instance) {
+		dest := shellQuote(fmt.Sprintf("%s/%s", bundlerCheckoutsDir, p.ApplicationName))
+		switch {
+		case p.Tarball != nil:
+			fmt.Fprintf(&script, "curl -fsSL --retry 5 -o /tmp/source.tar.gz %s\n", shellQuote(p.Tarball.URL))
+			fmt.Fprintf(&script, "echo %s | sha256sum -c -\n", shellQuote(p.Tarball.SHA256+"  /tmp/source.tar.gz"))
+			fmt.Fprintf(&script, "extract %s /tmp/source.tar.gz\n", dest)
+		case p.ConfigMap != nil:
+			source := fmt.Sprintf("%s/%s", bundlerSourcesDir, p.ApplicationName)
+			volumes = append(volumes, v1.Volume{
+				Name: sourceVolumeName(p.ApplicationName),
+				VolumeSource: v1.VolumeSource{
+					ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{Name: p.ConfigMap.Name},
+						Items: []v1.KeyToPath{
+							{
+								Key:  p.ConfigMap.Key,
+								Path: "source.tar.gz",
+							},
+						},
+					},
+				},
+			})
+			mounts = append(mounts, v1.VolumeMount{
+				Name:      sourceVolumeName(p.ApplicationName),
+				MountPath: source,
+				ReadOnly:  true,
+			})
+			fmt.Fprintf(&script, "extract %s %s\n", dest, shellQuote(source+"/source.tar.gz"))
+		}
+	}
+	if script.Len() == 0 {
+		return nil, nil, nil, nil
+	}
+
+	volumes = append(volumes, v1.Volume{
+		Name: bundlerCheckoutsVolumeName,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+	container := &v1.Container{
+		Name:         "fetch-sources",
+		Image:        image,
+		Command:      []string{"/bin/sh", "-c", "set -e\n" + extractSourceFunc + script.String()},
+		VolumeMounts: mounts,
+	}
+	checkoutsMount := []v1.VolumeMount{
+		{
+			Name:      bundlerCheckoutsVolumeName,
+			MountPath: bundlerCheckoutsDir,
+		},
+	}
+	env := []v1.EnvVar{
+		{
+			Name:  "REBAR_CHECKOUTS_DIR",
+			Value: bundlerCheckoutsDir,
+		},
+	}
+	return container, volumes, checkoutsMount, env
+}
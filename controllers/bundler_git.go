@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	bundlerGitDir         = "/etc/vmq-bundler/git"
+	bundlerGitVolumeName  = "vmq-bundler-git"
+	bundlerCredentialsDir = bundlerGitDir + "/credentials"
+)
+
+// scpLikeURLRegexp matches SSH URLs in the scp-like syntax, e.g. git@github.com:org/repo.git
+var scpLikeURLRegexp = regexp.MustCompile(`^(?:([^@/]+)@)?([^:/]+):(.+)$`)
+
+func bundlerGitConfigMapName(name string) string {
+	return fmt.Sprintf("%s-bundler-git", prefixedName(name))
+}
+
+func gitCredentialsVolumeName(applicationName string) string {
+	return fmt.Sprintf("git-credentials-%s", strings.ReplaceAll(applicationName, "_", "-"))
+}
+
+// pluginsWithCredentials returns the external plugins fetched from private repositories
+func pluginsWithCredentials(instance *vernemqv1alpha1.VerneMQ) []vernemqv1alpha1.PluginSource {
+	var plugins []vernemqv1alpha1.PluginSource
+	for _, p := range bundledPlugins(instance) {
+		if p.SecretRef != nil && p.SecretRef.Name != "" {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// makeBundlerGitConfigMap returns the git and ssh config used by the bundler to
+// fetch plugins from private repositories. It only references the paths the
+// credentials are mounted to, the credentials themselves stay in their Secrets.
+func makeBundlerGitConfigMap(instance *vernemqv1alpha1.VerneMQ) *v1.ConfigMap {
+	var gitConfig, sshConfig strings.Builder
+	fmt.Fprintf(&gitConfig, "[core]\n\tsshCommand = ssh -F %s/ssh_config\n", bundlerGitDir)
+	for _, p := range pluginsWithCredentials(instance) {
+		credentials := fmt.Sprintf("%s/%s", bundlerCredentialsDir, p.ApplicationName)
+		if u, err := url.Parse(p.RepoURL); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+			// only answer `get` requests of git for this repository
+			fmt.Fprintf(&gitConfig, "[credential %q]\n\tuseHttpPath = true\n", p.RepoURL)
+			fmt.Fprintf(&gitConfig, "\thelper = \"!f() { test \\\"$1\\\" = get && echo username=$(cat %[1]s/username) && echo password=$(cat %[1]s/password); }; f\"\n", credentials)
+			continue
+		}
+		user, host, port, path, ok := parseSSHURL(p.RepoURL)
+		if !ok {
+			continue
+		}
+		// route the repository through a host alias using the plugin's deploy key
+		alias := fmt.Sprintf("vmq-plugin-%s", p.ApplicationName)
+		fmt.Fprintf(&gitConfig, "[url %q]\n\tinsteadOf = %s\n", fmt.Sprintf("ssh://%s@%s/%s", user, alias, path), p.RepoURL)
+		fmt.Fprintf(&sshConfig, "Host %s\n\tHostName %s\n\tPort %s\n\tUser %s\n", alias, host, port, user)
+		fmt.Fprintf(&sshConfig, "\tIdentityFile %s/ssh-privatekey\n\tIdentitiesOnly yes\n", credentials)
+		fmt.Fprintf(&sshConfig, "\tUserKnownHostsFile %s/known_hosts\n\tStrictHostKeyChecking yes\n", credentials)
+	}
+
+	boolTrue := true
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bundlerGitConfigMapName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		Data: map[string]string{
+			"gitconfig":  gitConfig.String(),
+			"ssh_config": sshConfig.String(),
+		},
+	}
+}
+
+// parseSSHURL splits ssh://user@host:port/path and user@host:path URLs
+func parseSSHURL(repoURL string) (user, host, port, path string, ok bool) {
+	user, port = "git", "22"
+	if u, err := url.Parse(repoURL); err == nil && u.Scheme == "ssh" {
+		if u.User != nil {
+			user = u.User.Username()
+		}
+		if u.Port() != "" {
+			port = u.Port()
+		}
+		return user, u.Hostname(), port, strings.TrimPrefix(u.Path, "/"), true
+	}
+	// URLs of other schemes aren't scp-like
+	m := scpLikeURLRegexp.FindStringSubmatch(repoURL)
+	if m == nil || strings.Contains(repoURL, "://") {
+		return "", "", "", "", false
+	}
+	if m[1] != "" {
+		user = m[1]
+	}
+	return user, m[2], port, m[3], true
+}
+
+// makeBundlerGitVolumes returns the volumes, mounts and environment that provide
+// the git credentials to the bundler container
+func makeBundlerGitVolumes(instance *vernemqv1alpha1.VerneMQ) ([]v1.Volume, []v1.VolumeMount, []v1.EnvVar) {
+	plugins := pluginsWithCredentials(instance)
+	if len(plugins) == 0 {
+		return nil, nil, nil
+	}
+	secretMode := int32(0400)
+	volumes := []v1.Volume{
+		{
+			Name: bundlerGitVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: bundlerGitConfigMapName(instance.Name)},
+				},
+			},
+		},
+	}
+	mounts := []v1.VolumeMount{
+		{
+			Name:      bundlerGitVolumeName,
+			MountPath: bundlerGitDir,
+			ReadOnly:  true,
+		},
+	}
+	for _, p := range plugins {
+		volumes = append(volumes, v1.Volume{
+			Name: gitCredentialsVolumeName(p.ApplicationName),
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName:  p.SecretRef.Name,
+					DefaultMode: &secretMode,
+				},
+			},
+		})
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      gitCredentialsVolumeName(p.ApplicationName),
+			MountPath: fmt.Sprintf("%s/%s", bundlerCredentialsDir, p.ApplicationName),
+			ReadOnly:  true,
+		})
+	}
+	env := []v1.EnvVar{
+		{
+			Name:  "GIT_CONFIG_GLOBAL",
+			Value: fmt.Sprintf("%s/gitconfig", bundlerGitDir),
+		},
+	}
+	return volumes, mounts, env
+}
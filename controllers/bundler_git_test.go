@@ -0,0 +1,32 @@
+package controllers
+
+import "testing"
+
+func TestParseSSHURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantUser string
+		wantHost string
+		wantPort string
+		wantPath string
+		wantOK   bool
+	}{
+		{"ssh://git@github.com/vernemq/plugin.git", "git", "github.com", "22", "vernemq/plugin.git", true},
+		{"ssh://deploy@git.example.com:2222/plugin.git", "deploy", "git.example.com", "2222", "plugin.git", true},
+		{"ssh://git.example.com/plugin.git", "git", "git.example.com", "22", "plugin.git", true},
+		{"git@github.com:vernemq/plugin.git", "git", "github.com", "22", "vernemq/plugin.git", true},
+		{"github.com:vernemq/plugin.git", "git", "github.com", "22", "vernemq/plugin.git", true},
+		{"https://github.com/vernemq/plugin.git", "", "", "", "", false},
+		{"git://github.com/vernemq/plugin.git", "", "", "", "", false},
+		{"/srv/git/plugin.git", "", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			user, host, port, path, ok := parseSSHURL(tt.url)
+			if ok != tt.wantOK || user != tt.wantUser || host != tt.wantHost || port != tt.wantPort || path != tt.wantPath {
+				t.Errorf("parseSSHURL() = %q, %q, %q, %q, %v, want %q, %q, %q, %q, %v",
+					user, host, port, path, ok, tt.wantUser, tt.wantHost, tt.wantPort, tt.wantPath, tt.wantOK)
+			}
+		})
+	}
+}
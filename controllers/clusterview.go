@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// nodeClusterReport is the part of the config status report of a node listing
+// the pods of the cluster members the node sees running
+type nodeClusterReport struct {
+	ClusterView []string `json:"clusterView"`
+}
+
+// reportedClusterViews returns the cluster view reported by each running pod.
+// Pods without a valid report are left out, reports of pods that aren't running
+// are outdated.
+func reportedClusterViews(configStatus *v1.ConfigMap, pods []v1.Pod) map[string][]string {
+	views := map[string][]string{}
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		data, ok := configStatus.Data[pod.Name]
+		if !ok {
+			continue
+		}
+		report := nodeClusterReport{}
+		// invalid reports are reported in the status of the VerneMQ object
+		if err := json.Unmarshal([]byte(data), &report); err != nil || report.ClusterView == nil {
+			continue
+		}
+		views[pod.Name] = uniqueSorted(report.ClusterView)
+	}
+	return views
+}
+
+// clusterMembers returns the pods any node sees as running cluster members, ordered by name
+func clusterMembers(views map[string][]string) []string {
+	var members []string
+	for _, view := range views {
+		members = append(members, view...)
+	}
+	return uniqueSorted(members)
+}
+
+// clusterPartitioned returns true if the running nodes don't see the same cluster
+// members
+func clusterPartitioned(views map[string][]string) bool {
+	members := clusterMembers(views)
+	for _, view := range views {
+		if !reflect.DeepEqual(view, members) {
+			return true
+		}
+	}
+	return false
+}
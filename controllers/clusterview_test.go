@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReportedClusterViews(t *testing.T) {
+	pod := func(name string, phase v1.PodPhase) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: v1.PodStatus{Phase: phase}}
+	}
+	configStatus := &v1.ConfigMap{Data: map[string]string{
+		"vernemq-a-0": `{"clusterView": ["vernemq-a-1", "vernemq-a-0"]}`,
+		"vernemq-a-1": `{"clusterView": ["vernemq-a-0", "vernemq-a-1", "vernemq-a-2"]}`,
+		"vernemq-a-2": `{"clusterView": ["vernemq-a-2"]}`,
+		"vernemq-a-3": `{"appliedConfigHash": "abc"}`,
+		"vernemq-a-4": `invalid`,
+	}}
+	pods := []v1.Pod{
+		pod("vernemq-a-0", v1.PodRunning),
+		pod("vernemq-a-1", v1.PodRunning),
+		pod("vernemq-a-2", v1.PodPending),
+		pod("vernemq-a-3", v1.PodRunning),
+		pod("vernemq-a-4", v1.PodRunning),
+	}
+	views := reportedClusterViews(configStatus, pods)
+	want := map[string][]string{
+		"vernemq-a-0": {"vernemq-a-0", "vernemq-a-1"},
+		"vernemq-a-1": {"vernemq-a-0", "vernemq-a-1", "vernemq-a-2"},
+	}
+	if !reflect.DeepEqual(views, want) {
+		t.Errorf("reportedClusterViews() = %v, want %v", views, want)
+	}
+	if got := clusterMembers(views); !reflect.DeepEqual(got, []string{"vernemq-a-0", "vernemq-a-1", "vernemq-a-2"}) {
+		t.Errorf("clusterMembers() = %v", got)
+	}
+}
+
+func TestClusterPartitioned(t *testing.T) {
+	tests := []struct {
+		name  string
+		views map[string][]string
+		want  bool
+	}{
+		{"no reports", nil, false},
+		{"same view", map[string][]string{
+			"vernemq-a-0": {"vernemq-a-0", "vernemq-a-1"},
+			"vernemq-a-1": {"vernemq-a-0", "vernemq-a-1"},
+		}, false},
+		{"node alone", map[string][]string{
+			"vernemq-a-0": {"vernemq-a-0", "vernemq-a-1"},
+			"vernemq-a-1": {"vernemq-a-0", "vernemq-a-1"},
+			"vernemq-a-2": {"vernemq-a-2"},
+		}, true},
+		{"node missing a member", map[string][]string{
+			"vernemq-a-0": {"vernemq-a-0", "vernemq-a-1", "vernemq-a-2"},
+			"vernemq-a-1": {"vernemq-a-0", "vernemq-a-1"},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterPartitioned(tt.views); got != tt.want {
+				t.Errorf("clusterPartitioned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordNodeEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileVerneMQ{recorder: recorder}
+	instance := &vernemqv1alpha1.VerneMQ{
+		Status: vernemqv1alpha1.VerneMQStatus{ClusterView: []string{"vernemq-a-0", "vernemq-a-2"}},
+	}
+	r.recordNodeEvents(instance, []string{"vernemq-a-0", "vernemq-a-1"})
+	close(recorder.Events)
+	var events []string
+	for e := range recorder.Events {
+		events = append(events, e)
+	}
+	want := []string{
+		"Normal NodeJoined Nodes joined the cluster: vernemq-a-2",
+		"Normal NodeLeft Nodes left the cluster: vernemq-a-1",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("recordNodeEvents() = %v, want %v", events, want)
+	}
+}
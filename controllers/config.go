@@ -1,20 +1,35 @@
 package controllers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 
 	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
-func makeConfigSecretFromSpec(instance *vernemqv1alpha1.VerneMQ) *v1.Secret {
+const (
+	configSecretName = "vernemq-yaml"
+	configFileName   = "config.yaml"
+)
+
+// makeConfigSecretFromSpec returns the Secret holding the reloadable config and
+// the files its commands read, e.g. the endpoints of webhooks with basic auth
+func makeConfigSecretFromSpec(instance *vernemqv1alpha1.VerneMQ, files map[string]string) *v1.Secret {
 	boolTrue := true
-	config := createStringData(instance)
+	stringData := map[string]string{configFileName: createStringData(instance)}
+	for name, content := range files {
+		stringData[name] = content
+	}
 	configSecret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   "vernemq-yaml",
+			Name:   configSecretName,
 			Labels: labelsForVerneMQ(instance.Name),
 			OwnerReferences: []metav1.OwnerReference{
 				{
@@ -28,7 +43,7 @@ func makeConfigSecretFromSpec(instance *vernemqv1alpha1.VerneMQ) *v1.Secret {
 			},
 		},
 		Type:       "Opaque",
-		StringData: map[string]string{"config.yaml": config},
+		StringData: stringData,
 	}
 	configSecret.Namespace = instance.Namespace
 	return configSecret
@@ -39,6 +54,173 @@ func createStringData(instance *vernemqv1alpha1.VerneMQ) string {
 	if err != nil {
 		return ""
 	}
-	fmt.Printf("========================\n %s\n======================\n", string(d))
 	return string(d)
 }
+
+func configStatusName(name string) string {
+	return fmt.Sprintf("%s-config-status", prefixedName(name))
+}
+
+// makeConfigStatusConfigMap returns the ConfigMap the vmq_k8s plugin of each node
+// reports the applied reloadable config to. Its data is owned by the plugin, the
+// format of the reports is described in docs/node-reports.md.
+func makeConfigStatusConfigMap(instance *vernemqv1alpha1.VerneMQ) *v1.ConfigMap {
+	boolTrue := true
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configStatusName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+	}
+}
+
+// makeConfigStatusRole returns the Role allowing the VerneMQ pods to write their
+// reports into the config status ConfigMap
+func makeConfigStatusRole(instance *vernemqv1alpha1.VerneMQ) *rbacv1.Role {
+	boolTrue := true
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configStatusName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{configStatusName(instance.Name)},
+				Verbs:         []string{"get", "update", "patch"},
+			},
+		},
+	}
+}
+
+// makeConfigStatusRoleBinding binds the config status Role to the ServiceAccount
+// of the VerneMQ pods
+func makeConfigStatusRoleBinding(instance *vernemqv1alpha1.VerneMQ) *rbacv1.RoleBinding {
+	boolTrue := true
+	serviceAccount := instance.Spec.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configStatusName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     configStatusName(instance.Name),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount,
+				Namespace: instance.Namespace,
+			},
+		},
+	}
+}
+
+func configHash(config string) string {
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:])
+}
+
+// makeNodeConfigStatus collects the config status reported by each pod
+func makeNodeConfigStatus(configStatus *v1.ConfigMap, pods []v1.Pod) []vernemqv1alpha1.NodeConfigStatus {
+	var nodes []vernemqv1alpha1.NodeConfigStatus
+	for _, pod := range pods {
+		node := vernemqv1alpha1.NodeConfigStatus{}
+		if report, ok := configStatus.Data[pod.Name]; ok {
+			if err := json.Unmarshal([]byte(report), &node); err != nil {
+				node.Failures = []vernemqv1alpha1.ConfigApplyFailure{
+					{Kind: "report", Name: pod.Name, Message: fmt.Sprintf("invalid config status: %v", err)},
+				}
+			}
+		}
+		for i := range node.Failures {
+			node.Failures[i].Command = redactCredentials(node.Failures[i].Command)
+			node.Failures[i].Message = redactCredentials(node.Failures[i].Message)
+		}
+		node.Node = pod.Name
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Node < nodes[j].Node })
+	return nodes
+}
+
+// configAppliedCondition summarizes the config status of all nodes. It is Unknown
+// as long as there are no nodes or a node didn't report yet.
+func configAppliedCondition(instance *vernemqv1alpha1.VerneMQ) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               vernemqv1alpha1.ConditionConfigApplied,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.Generation,
+		Reason:             "Applied",
+	}
+	if len(instance.Status.ConfigStatus) == 0 {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "NoNodes"
+		condition.Message = "no VerneMQ pods are running"
+		return condition
+	}
+	var pending, missing []string
+	for _, node := range instance.Status.ConfigStatus {
+		if len(node.Failures) > 0 {
+			f := node.Failures[0]
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "ApplyFailed"
+			condition.Message = fmt.Sprintf("node %s failed to apply %s %s: %s", node.Node, f.Kind, f.Name, f.Message)
+			return condition
+		}
+		if node.AppliedConfigHash == "" {
+			missing = append(missing, node.Node)
+		} else if node.AppliedConfigHash != instance.Status.ConfigHash {
+			pending = append(pending, node.Node)
+		}
+	}
+	if len(pending) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Pending"
+		condition.Message = fmt.Sprintf("waiting for nodes to apply the config: %v", pending)
+	} else if len(missing) > 0 {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "NotReported"
+		condition.Message = fmt.Sprintf("waiting for nodes to report the applied config: %v", missing)
+	}
+	return condition
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigAppliedCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		nodes      []vernemqv1alpha1.NodeConfigStatus
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "no nodes",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: "NoNodes",
+		},
+		{
+			name: "all nodes applied the config",
+			nodes: []vernemqv1alpha1.NodeConfigStatus{
+				{Node: "vernemq-a-0", AppliedConfigHash: "current"},
+				{Node: "vernemq-a-1", AppliedConfigHash: "current"},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Applied",
+		},
+		{
+			name: "a node didn't report",
+			nodes: []vernemqv1alpha1.NodeConfigStatus{
+				{Node: "vernemq-a-0", AppliedConfigHash: "current"},
+				{Node: "vernemq-a-1"},
+			},
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: "NotReported",
+		},
+		{
+			name: "a node applied an older config",
+			nodes: []vernemqv1alpha1.NodeConfigStatus{
+				{Node: "vernemq-a-0", AppliedConfigHash: "previous"},
+				{Node: "vernemq-a-1"},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Pending",
+		},
+		{
+			name: "a node failed to apply an item",
+			nodes: []vernemqv1alpha1.NodeConfigStatus{
+				{Node: "vernemq-a-0", AppliedConfigHash: "current", Failures: []vernemqv1alpha1.ConfigApplyFailure{{Kind: "plugin", Name: "vmq_bridge"}}},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "ApplyFailed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				Status: vernemqv1alpha1.VerneMQStatus{ConfigHash: "current", ConfigStatus: tt.nodes},
+			}
+			got := configAppliedCondition(instance)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("configAppliedCondition() = %s/%s, want %s/%s", got.Status, got.Reason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
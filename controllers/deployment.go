@@ -2,11 +2,20 @@ package controllers
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	bundlerCacheDir        = "/vmq-bundler/cache"
+	bundlerCacheVolumeName = "vmq-bundler-cache"
+	bundlerHTTPPort        = 80
 )
 
 func makeDeployment(instance *vernemqv1alpha1.VerneMQ) *appsv1.Deployment {
@@ -53,38 +62,99 @@ func makeDeploymentSpec(instance *vernemqv1alpha1.VerneMQ) *appsv1.DeploymentSpe
 
 	podLabels := map[string]string{"app": "vmq-bundler"}
 	podAnnotations := map[string]string{}
+	gitVolumes, gitVolumeMounts, gitEnv := makeBundlerGitVolumes(instance)
+	checkoutsContainer, checkoutsVolumes, checkoutsVolumeMounts, checkoutsEnv := makeBundlerCheckouts(instance, bundlerImage)
+	lockVolume, lockVolumeMount, lockEnv := makeBundlerLockVolume(instance)
+	bundler := instance.Spec.Bundler
+	if bundler == nil {
+		bundler = &vernemqv1alpha1.BundlerSpec{}
+	}
+	boolFalse := false
+	containerSecurityContext := &v1.SecurityContext{
+		AllowPrivilegeEscalation: &boolFalse,
+	}
+	cache := v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}
+	if bundler.Cache != nil {
+		cache = *bundler.Cache
+	}
+	volumes := []v1.Volume{
+		lockVolume,
+		{
+			Name:         bundlerCacheVolumeName,
+			VolumeSource: cache,
+		},
+	}
+	volumeMounts := []v1.VolumeMount{
+		lockVolumeMount,
+		{
+			Name:      bundlerCacheVolumeName,
+			MountPath: bundlerCacheDir,
+		},
+	}
+	var initContainers []v1.Container
+	if checkoutsContainer != nil {
+		checkoutsContainer.SecurityContext = containerSecurityContext
+		initContainers = append(initContainers, *checkoutsContainer)
+	}
 
 	return &appsv1.DeploymentSpec{
+		Replicas: bundler.Replicas,
 		Selector: &metav1.LabelSelector{
 			MatchLabels: podLabels,
 		},
 		Template: v1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Labels:      podLabels,
+				Labels:      bundlerPodLabels(instance.Name),
 				Annotations: podAnnotations,
 			},
 			Spec: v1.PodSpec{
+				Volumes:          append(append(volumes, gitVolumes...), checkoutsVolumes...),
+				InitContainers:   initContainers,
+				NodeSelector:     bundler.NodeSelector,
+				Tolerations:      bundler.Tolerations,
+				Affinity:         bundler.Affinity,
+				ImagePullSecrets: append(append([]v1.LocalObjectReference{}, instance.Spec.ImagePullSecrets...), bundler.ImagePullSecrets...),
+				SecurityContext:  bundler.SecurityContext,
 				Containers: []v1.Container{
 					{
-						Name:  "vmq-bundler",
-						Image: bundlerImage,
+						Name:            "vmq-bundler",
+						Image:           bundlerImage,
+						Resources:       bundler.Resources,
+						SecurityContext: containerSecurityContext,
 						Ports: []v1.ContainerPort{
 							{
 								Name:          "http",
-								ContainerPort: 80,
+								ContainerPort: bundlerHTTPPort,
 								Protocol:      v1.ProtocolTCP,
 							},
 						},
-						Env: []v1.EnvVar{
+						// the bundle is only served once it is built
+						ReadinessProbe: &v1.Probe{
+							ProbeHandler: v1.ProbeHandler{
+								HTTPGet: &v1.HTTPGetAction{
+									Path: "/bundle.tar.gz",
+									Port: intstr.FromString("http"),
+								},
+							},
+							PeriodSeconds:  10,
+							TimeoutSeconds: probeTimeoutSeconds,
+						},
+						VolumeMounts: append(append(volumeMounts, gitVolumeMounts...), checkoutsVolumeMounts...),
+						Env: append([]v1.EnvVar{
 							{
 								Name:  "BUNDLER_CONFIG",
 								Value: makeBundlerConfig(instance),
 							},
 							{
 								Name:  "HTTP_PORT",
-								Value: "80",
+								Value: strconv.Itoa(bundlerHTTPPort),
 							},
-						},
+							lockEnv,
+							{
+								Name:  "REBAR_CACHE_DIR",
+								Value: bundlerCacheDir,
+							},
+						}, append(gitEnv, checkoutsEnv...)...),
 					},
 				},
 			},
@@ -92,19 +162,79 @@ func makeDeploymentSpec(instance *vernemqv1alpha1.VerneMQ) *appsv1.DeploymentSpe
 	}
 }
 
+// bundlerPodLabels are the labels of the bundler pods of the instance. The
+// selector of the Deployment only matches the app label, it can't be changed on
+// existing Deployments.
+func bundlerPodLabels(name string) map[string]string {
+	return map[string]string{"app": "vmq-bundler", "vernemq": name}
+}
+
 func makeBundlerConfig(instance *vernemqv1alpha1.VerneMQ) string {
-	config := `
+	config := fmt.Sprintf(`
 {plugins, [
-	{rebar3_cargo, {git, "https://github.com/benoitc/rebar3_cargo", {ref, "85353035"}}}
+	{rebar3_cargo, {git, "https://github.com/benoitc/rebar3_cargo", {ref, "%s"}}}
 ]}.
 {deps, [
-	`
+	`, rebar3CargoRef)
 	for _, p := range instance.Spec.ExternalPlugins {
-		config = config + fmt.Sprintf("{%s, {git, \"%s\", {%s, \"%s\"}}},\n", p.ApplicationName, p.RepoURL, p.VersionType, p.Version)
+		config = config + pluginDep(p) + ",\n"
 	}
-	config = config + `
-	{vmq_k8s, {git, "https://github.com/vernemq/vmq-operator", {branch, "master"}}}
+	config = config + pluginDep(vmqK8sSource(instance)) + `
 ]}.
 	`
 	return config
 }
+
+// bundledPlugins returns the external plugins and the vmq_k8s plugin built by the bundler
+func bundledPlugins(instance *vernemqv1alpha1.VerneMQ) []vernemqv1alpha1.PluginSource {
+	return append(append([]vernemqv1alpha1.PluginSource{}, instance.Spec.ExternalPlugins...), vmqK8sSource(instance))
+}
+
+// vmqK8sSource returns the source of the vmq_k8s plugin, pinned to the release
+// of the operator unless overridden in the spec
+func vmqK8sSource(instance *vernemqv1alpha1.VerneMQ) vernemqv1alpha1.PluginSource {
+	source := vernemqv1alpha1.PluginSource{
+		PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+			RepoURL:     "https://github.com/vernemq/vmq-operator",
+			VersionType: "tag",
+			Version:     vmqK8sVersion,
+		},
+	}
+	if instance.Spec.Bundler != nil && instance.Spec.Bundler.VmqK8s != nil {
+		source = *instance.Spec.Bundler.VmqK8s
+	}
+	source.ApplicationName = "vmq_k8s"
+	return source
+}
+
+// pluginDep renders the rebar3 dependency of an external plugin. Plugins from
+// tarballs and ConfigMaps are provided in the checkouts dir and only declared by
+// name, the comment with the digest of their source makes the bundler rebuild
+// when it changes.
+func pluginDep(p vernemqv1alpha1.PluginSource) string {
+	switch {
+	case p.Hex != nil && p.Hex.Package != "" && p.Hex.Package != p.ApplicationName && p.Hex.Version != "":
+		return fmt.Sprintf("{%s, %s, {pkg, %s}}", p.ApplicationName, erlString(p.Hex.Version), p.Hex.Package)
+	case p.Hex != nil && p.Hex.Package != "" && p.Hex.Package != p.ApplicationName:
+		return fmt.Sprintf("{%s, {pkg, %s}}", p.ApplicationName, p.Hex.Package)
+	case p.Hex != nil && p.Hex.Version != "":
+		return fmt.Sprintf("{%s, %s}", p.ApplicationName, erlString(p.Hex.Version))
+	case p.Hex != nil:
+		return p.ApplicationName
+	case p.Tarball != nil:
+		return fmt.Sprintf("%%%% tarball %s sha256 %s\n%s", erlString(p.Tarball.URL), erlString(p.Tarball.SHA256), p.ApplicationName)
+	case p.ConfigMap != nil:
+		return fmt.Sprintf("%%%% configmap %s key %s sha256 %s\n%s", p.ConfigMap.Name, p.ConfigMap.Key, p.ConfigMap.Digest, p.ApplicationName)
+	}
+	versionType := p.VersionType
+	if versionType == "commit" {
+		versionType = "ref"
+	}
+	return fmt.Sprintf("{%s, {git, %s, {%s, %s}}}", p.ApplicationName, erlString(p.RepoURL), versionType, erlString(p.Version))
+}
+
+// erlString quotes s as an Erlang string on a single line
+func erlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
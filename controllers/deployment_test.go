@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+)
+
+func TestPluginDep(t *testing.T) {
+	tests := []struct {
+		name   string
+		source vernemqv1alpha1.PluginSource
+		want   string
+	}{
+		{
+			name: "git tag",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				RepoURL: "https://github.com/example/myplugin", VersionType: "tag", Version: "v1.0.0",
+			}},
+			want: `{myplugin, {git, "https://github.com/example/myplugin", {tag, "v1.0.0"}}}`,
+		},
+		{
+			name: "git commit is rendered as ref",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				RepoURL: "https://github.com/example/myplugin", VersionType: "commit", Version: "0a1b2c",
+			}},
+			want: `{myplugin, {git, "https://github.com/example/myplugin", {ref, "0a1b2c"}}}`,
+		},
+		{
+			name: "git strings are escaped",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				RepoURL: `https://host/a"b\c`, VersionType: "branch", Version: "main\"}}]}.\n{evil",
+			}},
+			want: `{myplugin, {git, "https://host/a\"b\\c", {branch, "main\"}}]}.\n{evil"}}}`,
+		},
+		{
+			name: "hex package with version",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				Hex: &vernemqv1alpha1.HexSource{Package: "my_package", Version: "~> 1.0"},
+			}},
+			want: `{myplugin, "~> 1.0", {pkg, my_package}}`,
+		},
+		{
+			name: "hex package of the same name",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				Hex: &vernemqv1alpha1.HexSource{Version: "1.0.0"},
+			}},
+			want: `{myplugin, "1.0.0"}`,
+		},
+		{
+			name: "tarball",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				Tarball: &vernemqv1alpha1.TarballSource{URL: "https://host/myplugin.tar.gz", SHA256: "abc"},
+			}},
+			want: "%% tarball \"https://host/myplugin.tar.gz\" sha256 \"abc\"\nmyplugin",
+		},
+		{
+			name: "configmap includes the digest of its content",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				ConfigMap: &vernemqv1alpha1.ConfigMapSource{Name: "myplugin-source", Key: "myplugin.tar.gz", Digest: "def"},
+			}},
+			want: "%% configmap myplugin-source key myplugin.tar.gz sha256 def\nmyplugin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pluginDep(tt.source); got != tt.want {
+				t.Errorf("pluginDep() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
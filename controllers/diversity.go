@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	diversityScriptsDir        = "/vernemq/etc/diversity"
+	diversityScriptsVolumeName = "vernemq-diversity-scripts"
+)
+
+var invalidScriptNameRegexp = regexp.MustCompile(`[^a-z0-9_]`)
+
+// diversityHashMethods lists the password hash methods supported by each backend
+var diversityHashMethods = map[string][]string{
+	"postgres":    {"crypt", "bcrypt", "sha256"},
+	"cockroachdb": {"bcrypt", "sha256"},
+	"mysql":       {"password", "md5", "sha1", "sha256"},
+}
+
+// validateDiversity checks the settings the CRD schema can't check on its own
+func validateDiversity(d *vernemqv1alpha1.DiversitySpec) error {
+	if d.PasswordHashMethod != "" && !containsString(diversityHashMethods[d.Backend], d.PasswordHashMethod) {
+		if len(diversityHashMethods[d.Backend]) == 0 {
+			return fmt.Errorf("vmq_diversity backend %s doesn't support a password hash method", d.Backend)
+		}
+		return fmt.Errorf("vmq_diversity backend %s doesn't support password hash method %s, supported are %v",
+			d.Backend, d.PasswordHashMethod, diversityHashMethods[d.Backend])
+	}
+	return nil
+}
+
+// diversityConfEntries renders the vmq_diversity settings. The credentials are
+// read from the mounted Secret when the pods start.
+func diversityConfEntries(d *vernemqv1alpha1.DiversitySpec) []confEntry {
+	prefix := fmt.Sprintf("vmq_diversity.%s", d.Backend)
+	entries := []confEntry{
+		{"plugins.vmq_diversity", "on"},
+		{fmt.Sprintf("vmq_diversity.auth_%s.enabled", d.Backend), "on"},
+		{prefix + ".host", d.Host},
+	}
+	if d.Port != nil {
+		entries = append(entries, confEntry{prefix + ".port", fmt.Sprint(*d.Port)})
+	}
+	if d.Database != "" {
+		entries = append(entries, confEntry{prefix + ".database", d.Database})
+	}
+	if d.PoolSize != nil {
+		entries = append(entries, confEntry{prefix + ".pool_size", fmt.Sprint(*d.PoolSize)})
+	}
+	if d.SSL != nil && (d.Backend == "postgres" || d.Backend == "cockroachdb") {
+		entries = append(entries, confEntry{prefix + ".ssl", onOff(*d.SSL)})
+	}
+	if d.PasswordHashMethod != "" {
+		entries = append(entries, confEntry{prefix + ".password_hash_method", d.PasswordHashMethod})
+	}
+	if d.AuthSource != "" && d.Backend == "mongodb" {
+		entries = append(entries, confEntry{prefix + ".auth_source", d.AuthSource})
+	}
+	if ref := d.CredentialsSecret; ref != nil {
+		switch d.Backend {
+		case "redis":
+		case "mongodb":
+			entries = append(entries, confEntry{prefix + ".login", secretFileValue(ref.Name, "username")})
+		default:
+			entries = append(entries, confEntry{prefix + ".user", secretFileValue(ref.Name, "username")})
+		}
+		entries = append(entries, confEntry{prefix + ".password", secretFileValue(ref.Name, "password")})
+	}
+	if d.Scripts != nil {
+		for _, key := range d.Scripts.Keys {
+			entries = append(entries, confEntry{
+				fmt.Sprintf("vmq_diversity.%s.file", diversityScriptName(key)),
+				path.Join(diversityScriptsDir, key),
+			})
+		}
+	}
+	return entries
+}
+
+// diversityScriptName derives the name of a user script from its ConfigMap key
+func diversityScriptName(key string) string {
+	return invalidScriptNameRegexp.ReplaceAllString(strings.ToLower(strings.TrimSuffix(key, ".lua")), "_")
+}
+
+// makeDiversityScriptsVolume returns the volume and mount of the Lua scripts, if any
+func makeDiversityScriptsVolume(instance *vernemqv1alpha1.VerneMQ) ([]v1.Volume, []v1.VolumeMount) {
+	if instance.Spec.Auth == nil || instance.Spec.Auth.Diversity == nil || instance.Spec.Auth.Diversity.Scripts == nil {
+		return nil, nil
+	}
+	volume := v1.Volume{
+		Name: diversityScriptsVolumeName,
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: instance.Spec.Auth.Diversity.Scripts.Name},
+			},
+		},
+	}
+	mount := v1.VolumeMount{
+		Name:      diversityScriptsVolumeName,
+		ReadOnly:  true,
+		MountPath: diversityScriptsDir,
+	}
+	return []v1.Volume{volume}, []v1.VolumeMount{mount}
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestDiversityConfEntries(t *testing.T) {
+	creds := &v1.LocalObjectReference{Name: "db-creds"}
+	ssl := true
+	tests := []struct {
+		name string
+		spec vernemqv1alpha1.DiversitySpec
+		want map[string]string
+		skip []string
+	}{
+		{
+			name: "postgres credentials are read from the mounted secret",
+			spec: vernemqv1alpha1.DiversitySpec{Backend: "postgres", Host: "db", CredentialsSecret: creds},
+			want: map[string]string{
+				"vmq_diversity.auth_postgres.enabled": "on",
+				"vmq_diversity.postgres.user":         secretFilePrefix + "/vernemq/etc/secrets/db-creds/username",
+				"vmq_diversity.postgres.password":     secretFilePrefix + "/vernemq/etc/secrets/db-creds/password",
+			},
+		},
+		{
+			name: "cockroachdb uses its own keys",
+			spec: vernemqv1alpha1.DiversitySpec{Backend: "cockroachdb", Host: "db", SSL: &ssl, PasswordHashMethod: "sha256"},
+			want: map[string]string{
+				"vmq_diversity.cockroachdb.host":                 "db",
+				"vmq_diversity.cockroachdb.ssl":                  "on",
+				"vmq_diversity.cockroachdb.password_hash_method": "sha256",
+			},
+			skip: []string{"vmq_diversity.postgres.host"},
+		},
+		{
+			name: "mongodb logs in",
+			spec: vernemqv1alpha1.DiversitySpec{Backend: "mongodb", Host: "db", CredentialsSecret: creds},
+			want: map[string]string{
+				"vmq_diversity.mongodb.login": secretFilePrefix + "/vernemq/etc/secrets/db-creds/username",
+			},
+			skip: []string{"vmq_diversity.mongodb.user"},
+		},
+		{
+			name: "redis only uses the password",
+			spec: vernemqv1alpha1.DiversitySpec{Backend: "redis", Host: "db", CredentialsSecret: creds},
+			want: map[string]string{
+				"vmq_diversity.redis.password": secretFilePrefix + "/vernemq/etc/secrets/db-creds/password",
+			},
+			skip: []string{"vmq_diversity.redis.user", "vmq_diversity.redis.login"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			for _, e := range diversityConfEntries(&tt.spec) {
+				got[e.key] = e.value
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("diversityConfEntries() %s = %q, want %q", key, got[key], value)
+				}
+			}
+			for _, key := range tt.skip {
+				if _, ok := got[key]; ok {
+					t.Errorf("diversityConfEntries() sets %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDiversity(t *testing.T) {
+	tests := []struct {
+		backend string
+		method  string
+		wantErr bool
+	}{
+		{backend: "postgres", method: "crypt"},
+		{backend: "postgres", method: "md5", wantErr: true},
+		{backend: "cockroachdb", method: "bcrypt"},
+		{backend: "cockroachdb", method: "crypt", wantErr: true},
+		{backend: "mysql", method: "sha1"},
+		{backend: "mysql", method: "bcrypt", wantErr: true},
+		{backend: "mongodb", method: "bcrypt", wantErr: true},
+		{backend: "redis"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend+"/"+tt.method, func(t *testing.T) {
+			err := validateDiversity(&vernemqv1alpha1.DiversitySpec{Backend: tt.backend, PasswordHashMethod: tt.method})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDiversity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
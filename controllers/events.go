@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// recordRolloutEvents records version changes and scaling steps of the StatefulSet
+// about to be written
+func (r *ReconcileVerneMQ) recordRolloutEvents(ctx context.Context, instance *vernemqv1alpha1.VerneMQ, statefulset *appsv1.StatefulSet) error {
+	existing := &appsv1.StatefulSet{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: statefulset.Name, Namespace: statefulset.Namespace}, existing)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return pkgerr.Wrap(err, "reading statefulset failed")
+	}
+
+	from, to := vernemqImage(existing), vernemqImage(statefulset)
+	if from != "" && to != "" && from != to {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "VersionChanged", "Rolling VerneMQ from %s to %s", from, to)
+	}
+	if existing.Spec.Replicas != nil && statefulset.Spec.Replicas != nil && *existing.Spec.Replicas != *statefulset.Spec.Replicas {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "Scaling", "Scaling from %d to %d nodes", *existing.Spec.Replicas, *statefulset.Spec.Replicas)
+	}
+	return nil
+}
+
+func vernemqImage(statefulset *appsv1.StatefulSet) string {
+	for _, c := range statefulset.Spec.Template.Spec.Containers {
+		if c.Name == vernemqName {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// recordNodeEvents records the nodes that joined or left the cluster view reported
+// by the nodes since the observed status
+func (r *ReconcileVerneMQ) recordNodeEvents(instance *vernemqv1alpha1.VerneMQ, observed []string) {
+	before := map[string]bool{}
+	for _, node := range observed {
+		before[node] = true
+	}
+	var joined []string
+	for _, node := range instance.Status.ClusterView {
+		if !before[node] {
+			joined = append(joined, node)
+		}
+		delete(before, node)
+	}
+	if len(joined) > 0 {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "NodeJoined", "Nodes joined the cluster: %s", strings.Join(joined, ", "))
+	}
+	if len(before) > 0 {
+		r.recorder.Eventf(instance, v1.EventTypeNormal, "NodeLeft", "Nodes left the cluster: %s", strings.Join(sortedKeys(before), ", "))
+	}
+}
+
+// recordBundleEvents records a failed build once when the bundle status reports it
+func (r *ReconcileVerneMQ) recordBundleEvents(instance *vernemqv1alpha1.VerneMQ, observed *vernemqv1alpha1.BundleStatus) {
+	bundle := instance.Status.Bundle
+	if bundle == nil || bundle.BuildState != vernemqv1alpha1.BundleFailed {
+		return
+	}
+	if observed != nil && observed.BuildState == vernemqv1alpha1.BundleFailed && observed.BuildTime.Equal(bundle.BuildTime) {
+		return
+	}
+	r.recorder.Eventf(instance, v1.EventTypeWarning, "BundleBuildFailed", "Building the plugin bundle failed: %s", strings.Join(bundle.Errors, "; "))
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const federationBridgePrefix = "federation-"
+
+// memberNameRegexp matches valid federation member names, they become part of
+// the bridge names in vernemq.conf
+var memberNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func federationMemberSecretName(name string) string {
+	return fmt.Sprintf("%s-federation-member", prefixedName(name))
+}
+
+// federationEndpoint returns the endpoint advertised to the peers as "host:port"
+func federationEndpoint(instance *vernemqv1alpha1.VerneMQ) string {
+	a := instance.Spec.Federation.Advertise
+	port := int32(1883)
+	if a.Port != nil {
+		port = *a.Port
+	}
+	return net.JoinHostPort(a.Host, fmt.Sprint(port))
+}
+
+// makeFederationMemberSecret publishes the endpoint of this member by its member name
+func makeFederationMemberSecret(instance *vernemqv1alpha1.VerneMQ) *v1.Secret {
+	boolTrue := true
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      federationMemberSecretName(instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		Type:       "Opaque",
+		StringData: map[string]string{instance.Spec.Federation.Name: federationEndpoint(instance)},
+	}
+}
+
+// federationPeers reads the endpoints of the peers by their member name. A missing
+// peers Secret is treated as a federation without peers.
+func (r *ReconcileVerneMQ) federationPeers(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) (map[string]string, error) {
+	if instance.Spec.Federation == nil {
+		return nil, nil
+	}
+	federation := instance.Spec.Federation
+	secret := &v1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: federation.PeersSecret.Name, Namespace: instance.Namespace}, secret)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "get federation peers")
+	}
+	peers := map[string]string{}
+	for name, endpoint := range secret.Data {
+		if name == federation.Name {
+			continue
+		}
+		peers[name] = strings.TrimSpace(string(endpoint))
+	}
+	return peers, nil
+}
+
+// federationBridges returns a bridge to each valid peer, ordered by peer name, and
+// the reason each other peer is skipped for by its name. The peers Secret may be
+// written by other clusters, so an invalid peer doesn't affect the other peers.
+func federationBridges(instance *vernemqv1alpha1.VerneMQ, peers map[string]string) ([]vernemqv1alpha1.VerneMQBridge, map[string]string, error) {
+	federation := instance.Spec.Federation
+	if federation == nil {
+		return nil, nil, nil
+	}
+	if err := validateBridge(vernemqv1alpha1.VerneMQBridge{Spec: vernemqv1alpha1.VerneMQBridgeSpec{Topics: federation.Topics}}); err != nil {
+		return nil, nil, pkgerr.Wrap(err, "invalid federation topics")
+	}
+	var bridges []vernemqv1alpha1.VerneMQBridge
+	rejected := map[string]string{}
+	for _, peer := range sortedKeys(peers) {
+		host, port, err := parsePeerEndpoint(peer, peers[peer])
+		if err != nil {
+			rejected[peer] = err.Error()
+			continue
+		}
+		bridges = append(bridges, vernemqv1alpha1.VerneMQBridge{
+			ObjectMeta: metav1.ObjectMeta{Name: federationBridgePrefix + peer, Namespace: instance.Namespace},
+			Spec: vernemqv1alpha1.VerneMQBridgeSpec{
+				VerneMQ:           instance.Name,
+				Host:              host,
+				Port:              port,
+				TLS:               federation.TLS,
+				CredentialsSecret: federation.CredentialsSecret,
+				Topics:            federation.Topics,
+			},
+		})
+	}
+	return bridges, rejected, nil
+}
+
+// parsePeerEndpoint checks the member name of a peer and splits its "host:port" endpoint
+func parsePeerEndpoint(peer string, endpoint string) (string, int32, error) {
+	if !memberNameRegexp.MatchString(peer) {
+		return "", 0, fmt.Errorf("invalid member name %q, it must consist of lower case alphanumeric characters or '-'", peer)
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, pkgerr.Wrap(err, "invalid endpoint")
+	}
+	if host == "" || strings.ContainsAny(host, " \t\n") {
+		return "", 0, fmt.Errorf("invalid host %q", host)
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || portNumber == 0 {
+		return "", 0, fmt.Errorf("invalid port %q", port)
+	}
+	return host, int32(portNumber), nil
+}
+
+// updateFederationStatus records the state of the bridge to each peer in the status
+// of the instance
+func updateFederationStatus(instance *vernemqv1alpha1.VerneMQ, peers map[string]string, rejected map[string]string, configStatus *v1.ConfigMap, pods []v1.Pod) {
+	if instance.Spec.Federation == nil {
+		instance.Status.Federation = nil
+		meta.RemoveStatusCondition(&instance.Status.Conditions, vernemqv1alpha1.ConditionFederationConnected)
+		return
+	}
+	reports := nodeBridgeReports(configStatus, pods)
+	instance.Status.Federation = nil
+	var disconnected []string
+	var invalid []string
+	for _, peer := range sortedKeys(peers) {
+		if reason, ok := rejected[peer]; ok {
+			instance.Status.Federation = append(instance.Status.Federation, vernemqv1alpha1.FederationPeerStatus{
+				Peer:     peer,
+				Endpoint: peers[peer],
+				Message:  reason,
+			})
+			invalid = append(invalid, peer)
+			continue
+		}
+		nodes, down := bridgeNodeStates(reports, federationBridgePrefix+peer)
+		instance.Status.Federation = append(instance.Status.Federation, vernemqv1alpha1.FederationPeerStatus{
+			Peer:      peer,
+			Endpoint:  peers[peer],
+			Nodes:     nodes,
+			Connected: len(down) == 0,
+		})
+		if len(down) > 0 {
+			disconnected = append(disconnected, peer)
+		}
+	}
+	condition := metav1.Condition{
+		Type:               vernemqv1alpha1.ConditionFederationConnected,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.Generation,
+		Reason:             "Connected",
+	}
+	if len(peers) == 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NoPeers"
+		condition.Message = fmt.Sprintf("secret %s holds no peers", instance.Spec.Federation.PeersSecret.Name)
+	} else if len(invalid) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidPeers"
+		condition.Message = fmt.Sprintf("peers %v of secret %s are invalid and not bridged", invalid, instance.Spec.Federation.PeersSecret.Name)
+	} else if len(disconnected) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disconnected"
+		condition.Message = fmt.Sprintf("not all nodes are connected to peers %v", disconnected)
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+}
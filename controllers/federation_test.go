@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFederationBridges(t *testing.T) {
+	instance := &vernemqv1alpha1.VerneMQ{
+		Spec: vernemqv1alpha1.VerneMQSpec{Federation: &vernemqv1alpha1.FederationSpec{
+			Name:      "west",
+			Advertise: vernemqv1alpha1.FederationEndpoint{Host: "west.example.com"},
+			Topics:    []vernemqv1alpha1.BridgeTopic{{Pattern: "shared/#", Direction: "both"}},
+		}},
+	}
+	tests := []struct {
+		name         string
+		peers        map[string]string
+		topics       []vernemqv1alpha1.BridgeTopic
+		wantHosts    []string
+		wantRejected []string
+		wantErr      bool
+	}{
+		{
+			name:      "ordered by peer name",
+			peers:     map[string]string{"north": "north.example.com:1883", "east": "[2001:db8::1]:8883"},
+			wantHosts: []string{"2001:db8::1", "north.example.com"},
+		},
+		{
+			name:         "invalid peer name",
+			peers:        map[string]string{"East_1": "east.example.com:1883", "north": "north.example.com:1883"},
+			wantHosts:    []string{"north.example.com"},
+			wantRejected: []string{"East_1"},
+		},
+		{
+			name:         "invalid endpoints",
+			peers:        map[string]string{"east": "east.example.com", "south": "south.example.com:http", "west": ":1883"},
+			wantRejected: []string{"east", "south", "west"},
+		},
+		{
+			name:    "invalid topics",
+			peers:   map[string]string{"north": "north.example.com:1883"},
+			topics:  []vernemqv1alpha1.BridgeTopic{{Pattern: "#", Direction: "out", RemotePrefix: "remote/"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := instance.DeepCopy()
+			if tt.topics != nil {
+				instance.Spec.Federation.Topics = tt.topics
+			}
+			bridges, rejected, err := federationBridges(instance, tt.peers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("federationBridges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(bridges) != len(tt.wantHosts) {
+				t.Fatalf("federationBridges() = %d bridges, want %d", len(bridges), len(tt.wantHosts))
+			}
+			for i, b := range bridges {
+				if b.Spec.Host != tt.wantHosts[i] {
+					t.Errorf("federationBridges()[%d] host = %s, want %s", i, b.Spec.Host, tt.wantHosts[i])
+				}
+			}
+			if got := sortedKeys(rejected); (len(got) > 0 || len(tt.wantRejected) > 0) && !reflect.DeepEqual(got, tt.wantRejected) {
+				t.Errorf("federationBridges() rejected = %v, want %v", got, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestUpdateFederationStatus(t *testing.T) {
+	instance := &vernemqv1alpha1.VerneMQ{
+		Spec: vernemqv1alpha1.VerneMQSpec{Federation: &vernemqv1alpha1.FederationSpec{
+			Name:        "west",
+			PeersSecret: v1.LocalObjectReference{Name: "peers"},
+		}},
+	}
+	peers := map[string]string{"east": "east.example.com", "north": "north.example.com:1883"}
+	updateFederationStatus(instance, peers, map[string]string{"east": "invalid endpoint"}, &v1.ConfigMap{}, nil)
+
+	want := []vernemqv1alpha1.FederationPeerStatus{
+		{Peer: "east", Endpoint: "east.example.com", Message: "invalid endpoint"},
+		{Peer: "north", Endpoint: "north.example.com:1883", Connected: true},
+	}
+	if !reflect.DeepEqual(instance.Status.Federation, want) {
+		t.Errorf("status.federation = %+v, want %+v", instance.Status.Federation, want)
+	}
+	condition := meta.FindStatusCondition(instance.Status.Conditions, vernemqv1alpha1.ConditionFederationConnected)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "InvalidPeers" {
+		t.Errorf("FederationConnected condition = %+v, want reason InvalidPeers", condition)
+	}
+}
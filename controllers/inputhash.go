@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"sort"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// staticInputHash returns a hash of all inputs VerneMQ only reads on startup:
+// vernemq.conf, vm.args, the environment and the content of the mounted Secrets
+// and ConfigMaps, including the vmq_diversity credentials and scripts. It is added
+// to the pod template, so that the StatefulSet is only rolled if one of them
+// changes. The reloadable config is applied without restart and therefore not
+// part of the hash.
+func (r *ReconcileVerneMQ) staticInputHash(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) (string, error) {
+	vernemqConf, err := makeGlobalVerneMQConf(instance)
+	if err != nil {
+		return "", pkgerr.Wrap(err, "make vernemq.conf")
+	}
+
+	h := sha256.New()
+	writeHashField(h, []byte(vernemqConf))
+	writeHashField(h, []byte(makeGlobalVMArgs(instance)))
+	env, err := json.Marshal(instance.Spec.Env)
+	if err != nil {
+		return "", pkgerr.Wrap(err, "marshal env")
+	}
+	writeHashField(h, env)
+
+	secrets := mountedSecrets(instance)
+	configMaps := append([]string{}, instance.Spec.ConfigMaps...)
+	if instance.Spec.Auth != nil && instance.Spec.Auth.Diversity != nil && instance.Spec.Auth.Diversity.Scripts != nil {
+		configMaps = append(configMaps, instance.Spec.Auth.Diversity.Scripts.Name)
+	}
+	// the values of env vars are only read on startup as well
+	for _, e := range instance.Spec.Env {
+		if e.ValueFrom == nil {
+			continue
+		}
+		if ref := e.ValueFrom.SecretKeyRef; ref != nil {
+			secrets = append(secrets, ref.Name)
+		}
+		if ref := e.ValueFrom.ConfigMapKeyRef; ref != nil {
+			configMaps = append(configMaps, ref.Name)
+		}
+	}
+
+	for _, name := range uniqueSorted(secrets) {
+		secret := &v1.Secret{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: instance.Namespace}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", pkgerr.Wrapf(err, "reading secret %s failed", name)
+		}
+		writeHashField(h, []byte("secret/"+name))
+		for _, k := range sortedKeys(secret.Data) {
+			writeHashField(h, []byte(k))
+			writeHashField(h, secret.Data[k])
+		}
+	}
+	for _, name := range uniqueSorted(configMaps) {
+		configMap := &v1.ConfigMap{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: instance.Namespace}, configMap)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", pkgerr.Wrapf(err, "reading configmap %s failed", name)
+		}
+		writeHashField(h, []byte("configmap/"+name))
+		for _, k := range sortedKeys(configMap.Data) {
+			writeHashField(h, []byte(k))
+			writeHashField(h, []byte(configMap.Data[k]))
+		}
+		for _, k := range sortedKeys(configMap.BinaryData) {
+			writeHashField(h, []byte("binary/"+k))
+			writeHashField(h, configMap.BinaryData[k])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeHashField writes b prefixed by its length, so that the boundaries of the
+// fields are part of the hash
+func writeHashField(h hash.Hash, b []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+	h.Write(length[:])
+	h.Write(b)
+}
+
+func uniqueSorted(list []string) []string {
+	set := map[string]bool{}
+	for _, s := range list {
+		set[s] = true
+	}
+	return sortedKeys(set)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
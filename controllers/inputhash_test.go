@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStaticInputHash(t *testing.T) {
+	hash := func(t *testing.T, instance *vernemqv1alpha1.VerneMQ, secretData map[string][]byte) string {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "messaging"},
+			Data:       secretData,
+		}
+		r := &ReconcileVerneMQ{client: fake.NewClientBuilder().WithObjects(secret).Build()}
+		h, err := r.staticInputHash(context.Background(), instance)
+		if err != nil {
+			t.Fatalf("staticInputHash() error = %v", err)
+		}
+		return h
+	}
+	withSecrets := &vernemqv1alpha1.VerneMQ{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "messaging"},
+		Spec:       vernemqv1alpha1.VerneMQSpec{Secrets: []string{"creds"}},
+	}
+	withEnv := &vernemqv1alpha1.VerneMQ{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "messaging"},
+		Spec: vernemqv1alpha1.VerneMQSpec{
+			Env: []v1.EnvVar{secretEnvVar("PASSWORD", v1.LocalObjectReference{Name: "creds"}, "password")},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		instance *vernemqv1alpha1.VerneMQ
+		a, b     map[string][]byte
+		same     bool
+	}{
+		{
+			name:     "unchanged secret",
+			instance: withSecrets,
+			a:        map[string][]byte{"password": []byte("secret")},
+			b:        map[string][]byte{"password": []byte("secret")},
+			same:     true,
+		},
+		{
+			name:     "key and value boundaries",
+			instance: withSecrets,
+			a:        map[string][]byte{"ab": []byte("c")},
+			b:        map[string][]byte{"a": []byte("bc")},
+		},
+		{
+			name:     "secret referenced by an env var",
+			instance: withEnv,
+			a:        map[string][]byte{"password": []byte("old")},
+			b:        map[string][]byte{"password": []byte("new")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := hash(t, tt.instance, tt.a), hash(t, tt.instance, tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("staticInputHash() equal = %v, want %v", a == b, tt.same)
+			}
+		})
+	}
+}
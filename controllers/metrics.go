@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "vmq_operator"
+
+// The phases of the upgrade_phase metric
+const (
+	upgradePhaseStable          = "Stable"
+	upgradePhaseRolling         = "Rolling"
+	upgradePhaseBlockedOnBundle = "BlockedOnBundle"
+)
+
+var upgradePhases = []string{upgradePhaseStable, upgradePhaseRolling, upgradePhaseBlockedOnBundle}
+
+var (
+	desiredNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "desired_nodes",
+		Help:      "Number of VerneMQ nodes requested by the spec",
+	}, []string{"namespace", "name"})
+	readyNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "ready_nodes",
+		Help:      "Number of ready VerneMQ pods of the StatefulSet",
+	}, []string{"namespace", "name"})
+	clusterViewNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "clusterview_nodes",
+		Help:      "Number of cluster members reported by the VerneMQ nodes",
+	}, []string{"namespace", "name"})
+	bundleBuildDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "bundle_build_duration_seconds",
+		Help:      "Duration of the plugin bundle builds by result",
+		Buckets:   prometheus.ExponentialBuckets(10, 2, 8),
+	}, []string{"namespace", "name", "result"})
+	configApplyFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "config_apply_failures",
+		Help:      "Number of reloadable config items the VerneMQ nodes failed to apply",
+	}, []string{"namespace", "name"})
+	upgradePhase = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "upgrade_phase",
+		Help:      "Current rollout phase of the VerneMQ StatefulSet, 1 for the current phase",
+	}, []string{"namespace", "name", "phase"})
+	lastSuccessfulReconcile = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_successful_reconcile_timestamp_seconds",
+		Help:      "Unix time of the last reconcile of the VerneMQ object that completed without error",
+	}, []string{"namespace", "name"})
+	objectWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "object_writes_total",
+		Help:      "Number of objects created or changed by the operator by kind",
+	}, []string{"kind", "operation"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		desiredNodes,
+		readyNodes,
+		clusterViewNodes,
+		bundleBuildDuration,
+		configApplyFailures,
+		upgradePhase,
+		lastSuccessfulReconcile,
+		objectWrites,
+	)
+}
+
+// recordClusterMetrics records the node counts and rollout phase of the instance.
+// The cluster view nodes are the members of the cluster view reported by the nodes.
+func recordClusterMetrics(instance *vernemqv1alpha1.VerneMQ, statefulset *appsv1.StatefulSet, bundleBlocked bool) {
+	labels := prometheus.Labels{"namespace": instance.Namespace, "name": instance.Name}
+	size := minSize
+	if instance.Spec.Size != nil {
+		size = *instance.Spec.Size
+	}
+	desiredNodes.With(labels).Set(float64(size))
+	readyNodes.With(labels).Set(float64(statefulset.Status.ReadyReplicas))
+	clusterViewNodes.With(labels).Set(float64(len(instance.Status.ClusterView)))
+
+	failures := 0
+	for _, s := range instance.Status.ConfigStatus {
+		failures += len(s.Failures)
+	}
+	configApplyFailures.With(labels).Set(float64(failures))
+
+	phase := upgradePhaseStable
+	if bundleBlocked {
+		phase = upgradePhaseBlockedOnBundle
+	} else if rolloutInProgress(statefulset) {
+		phase = upgradePhaseRolling
+	}
+	for _, p := range upgradePhases {
+		value := 0.0
+		if p == phase {
+			value = 1
+		}
+		upgradePhase.WithLabelValues(instance.Namespace, instance.Name, p).Set(value)
+	}
+}
+
+// recordBundleBuild observes the duration of a build once the bundle status
+// reports a build that finished after the previously observed one
+func recordBundleBuild(instance *vernemqv1alpha1.VerneMQ, observed *vernemqv1alpha1.BundleStatus, status *bundlerStatus) {
+	bundle := instance.Status.Bundle
+	if bundle == nil || bundle.BuildTime == nil {
+		return
+	}
+	if bundle.BuildState != vernemqv1alpha1.BundleSucceeded && bundle.BuildState != vernemqv1alpha1.BundleFailed {
+		return
+	}
+	if observed != nil && observed.BuildTime != nil && !bundle.BuildTime.After(observed.BuildTime.Time) {
+		return
+	}
+	bundleBuildDuration.WithLabelValues(instance.Namespace, instance.Name, bundle.BuildState).Observe(status.DurationSeconds)
+}
+
+func recordSuccessfulReconcile(instance *vernemqv1alpha1.VerneMQ) {
+	lastSuccessfulReconcile.WithLabelValues(instance.Namespace, instance.Name).Set(float64(time.Now().Unix()))
+}
+
+// forgetInstanceMetrics removes the series of a deleted instance
+func forgetInstanceMetrics(namespace string, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	for _, vec := range []*prometheus.GaugeVec{desiredNodes, readyNodes, clusterViewNodes, configApplyFailures, lastSuccessfulReconcile} {
+		vec.Delete(labels)
+	}
+	for _, p := range upgradePhases {
+		upgradePhase.DeleteLabelValues(namespace, name, p)
+	}
+	for _, result := range []string{vernemqv1alpha1.BundleSucceeded, vernemqv1alpha1.BundleFailed} {
+		bundleBuildDuration.DeleteLabelValues(namespace, name, result)
+	}
+}
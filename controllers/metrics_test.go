@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordClusterMetrics(t *testing.T) {
+	size := int32(3)
+	tests := []struct {
+		name            string
+		clusterView     []string
+		statefulset     appsv1.StatefulSetStatus
+		bundleBlocked   bool
+		wantReady       float64
+		wantClusterView float64
+		wantPhase       string
+	}{
+		{
+			name:            "stable",
+			clusterView:     []string{"vernemq-a-0", "vernemq-a-1", "vernemq-a-2"},
+			statefulset:     appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r1"},
+			wantReady:       3,
+			wantClusterView: 3,
+			wantPhase:       upgradePhaseStable,
+		},
+		{
+			name:            "rolling with a node out of the cluster",
+			clusterView:     []string{"vernemq-a-0", "vernemq-a-1"},
+			statefulset:     appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 2, UpdatedReplicas: 1, CurrentRevision: "r1", UpdateRevision: "r2"},
+			wantReady:       2,
+			wantClusterView: 2,
+			wantPhase:       upgradePhaseRolling,
+		},
+		{
+			name:          "blocked on bundle without reports",
+			statefulset:   appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r1"},
+			bundleBlocked: true,
+			wantReady:     3,
+			wantPhase:     upgradePhaseBlockedOnBundle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "messaging"},
+				Spec:       vernemqv1alpha1.VerneMQSpec{Size: &size},
+				Status:     vernemqv1alpha1.VerneMQStatus{ClusterView: tt.clusterView},
+			}
+			defer forgetInstanceMetrics(instance.Namespace, instance.Name)
+			recordClusterMetrics(instance, &appsv1.StatefulSet{Status: tt.statefulset}, tt.bundleBlocked)
+
+			if got := testutil.ToFloat64(desiredNodes.WithLabelValues("messaging", "metrics")); got != 3 {
+				t.Errorf("desired_nodes = %v, want 3", got)
+			}
+			if got := testutil.ToFloat64(readyNodes.WithLabelValues("messaging", "metrics")); got != tt.wantReady {
+				t.Errorf("ready_nodes = %v, want %v", got, tt.wantReady)
+			}
+			if got := testutil.ToFloat64(clusterViewNodes.WithLabelValues("messaging", "metrics")); got != tt.wantClusterView {
+				t.Errorf("clusterview_nodes = %v, want %v", got, tt.wantClusterView)
+			}
+			for _, phase := range upgradePhases {
+				want := 0.0
+				if phase == tt.wantPhase {
+					want = 1
+				}
+				if got := testutil.ToFloat64(upgradePhase.WithLabelValues("messaging", "metrics", phase)); got != want {
+					t.Errorf("upgrade_phase{phase=%q} = %v, want %v", phase, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	metricsPortName = "metrics"
+	metricsPath     = "/metrics"
+
+	defaultAlertFor        = "5m"
+	defaultQueueGrowthRate = int32(100)
+)
+
+var (
+	serviceMonitorKind = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	podMonitorKind     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+	prometheusRuleKind = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+)
+
+// reconcileMonitoring creates the Prometheus Operator objects requested by the
+// instance and deletes the others. Kinds whose CRD isn't installed are skipped.
+func (r *ReconcileVerneMQ) reconcileMonitoring(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) error {
+	desired := map[schema.GroupVersionKind]*unstructured.Unstructured{}
+	if m := instance.Spec.Monitoring; m != nil {
+		if m.Kind == podMonitorKind.Kind {
+			desired[podMonitorKind] = makePodMonitor(instance)
+		} else {
+			desired[serviceMonitorKind] = makeServiceMonitor(instance)
+		}
+		if m.Alerts != nil {
+			desired[prometheusRuleKind] = makePrometheusRule(instance)
+		}
+	}
+
+	for _, gvk := range []schema.GroupVersionKind{serviceMonitorKind, podMonitorKind, prometheusRuleKind} {
+		installed, err := r.kindInstalled(gvk)
+		if err != nil {
+			return err
+		}
+		if !installed {
+			if _, ok := desired[gvk]; ok {
+				r.logger.Info("CRD not installed, skipping monitoring object", "kind", gvk.Kind)
+			}
+			continue
+		}
+		if obj, ok := desired[gvk]; ok {
+			err = r.createOrUpdate(ctx, instance, obj.GetName(), obj.GetNamespace(), obj)
+			if err != nil {
+				return pkgerr.Wrapf(err, "generating %s failed", gvk.Kind)
+			}
+			continue
+		}
+		// only objects created for the instance are deleted
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		err = r.client.Get(ctx, types.NamespacedName{Name: prefixedName(instance.Name), Namespace: instance.Namespace}, obj)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return pkgerr.Wrapf(err, "reading %s failed", gvk.Kind)
+		}
+		if !metav1.IsControlledBy(obj, instance) {
+			continue
+		}
+		err = r.client.Delete(ctx, obj)
+		if err != nil && !errors.IsNotFound(err) {
+			return pkgerr.Wrapf(err, "deleting %s failed", gvk.Kind)
+		}
+	}
+	return nil
+}
+
+// kindInstalled checks whether the API server serves the kind
+func (r *ReconcileVerneMQ) kindInstalled(gvk schema.GroupVersionKind) (bool, error) {
+	_, err := r.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, pkgerr.Wrapf(err, "looking up %s failed", gvk.Kind)
+	}
+	return true, nil
+}
+
+func makeMonitoringObject(instance *vernemqv1alpha1.VerneMQ, gvk schema.GroupVersionKind, spec map[string]interface{}) *unstructured.Unstructured {
+	labels := map[string]interface{}{}
+	for k, v := range instance.Spec.Monitoring.Labels {
+		labels[k] = v
+	}
+	for k, v := range labelsForVerneMQ(instance.Name) {
+		labels[k] = v
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      prefixedName(instance.Name),
+			"namespace": instance.Namespace,
+			"labels":    labels,
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion":         instance.APIVersion,
+					"blockOwnerDeletion": true,
+					"controller":         true,
+					"kind":               instance.Kind,
+					"name":               instance.Name,
+					"uid":                string(instance.UID),
+				},
+			},
+		},
+		"spec": spec,
+	}}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+func metricsEndpoint(instance *vernemqv1alpha1.VerneMQ, port string) map[string]interface{} {
+	endpoint := map[string]interface{}{
+		"port": port,
+		"path": metricsPath,
+	}
+	if instance.Spec.Monitoring.Interval != "" {
+		endpoint["interval"] = instance.Spec.Monitoring.Interval
+	}
+	return endpoint
+}
+
+// makeServiceMonitor scrapes the metrics port of the headless VerneMQ Service
+func makeServiceMonitor(instance *vernemqv1alpha1.VerneMQ) *unstructured.Unstructured {
+	return makeMonitoringObject(instance, serviceMonitorKind, map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				"operated-vernemq": "true",
+				"vernemq":          instance.Name,
+			},
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{instance.Namespace},
+		},
+		"endpoints": []interface{}{metricsEndpoint(instance, metricsPortName)},
+	})
+}
+
+// makePodMonitor scrapes the HTTP listener of the VerneMQ pods
+func makePodMonitor(instance *vernemqv1alpha1.VerneMQ) *unstructured.Unstructured {
+	selector := map[string]interface{}{}
+	for k, v := range labelsForVerneMQ(instance.Name) {
+		selector[k] = v
+	}
+	return makeMonitoringObject(instance, podMonitorKind, map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": selector,
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{instance.Namespace},
+		},
+		"podMetricsEndpoints": []interface{}{metricsEndpoint(instance, "http")},
+	})
+}
+
+// makePrometheusRule returns the alerts for netsplits, growing queues, dropped
+// messages and nodes missing from the cluster
+func makePrometheusRule(instance *vernemqv1alpha1.VerneMQ) *unstructured.Unstructured {
+	alerts := instance.Spec.Monitoring.Alerts
+	forDuration := alerts.For
+	if forDuration == "" {
+		forDuration = defaultAlertFor
+	}
+	queueGrowthRate := defaultQueueGrowthRate
+	if alerts.QueueGrowthRate != nil {
+		queueGrowthRate = *alerts.QueueGrowthRate
+	}
+	size := minSize
+	if instance.Spec.Size != nil {
+		size = *instance.Spec.Size
+	}
+	selector := fmt.Sprintf(`namespace=%q,pod=~"%s-[0-9]+"`, instance.Namespace, prefixedName(instance.Name))
+
+	rule := func(name string, expr string, summary string) interface{} {
+		labels := map[string]interface{}{"severity": "warning"}
+		for k, v := range alerts.Labels {
+			labels[k] = v
+		}
+		return map[string]interface{}{
+			"alert":  name,
+			"expr":   expr,
+			"for":    forDuration,
+			"labels": labels,
+			"annotations": map[string]interface{}{
+				"summary": summary,
+			},
+		}
+	}
+	rules := []interface{}{
+		rule("VerneMQNetsplitDetected",
+			fmt.Sprintf(`sum by (pod) (increase(vernemq_netsplit_detected{%s}[5m])) > sum by (pod) (increase(vernemq_netsplit_resolved{%s}[5m]))`, selector, selector),
+			fmt.Sprintf("VerneMQ %s/%s detected a netsplit on {{ $labels.pod }}", instance.Namespace, instance.Name)),
+		rule("VerneMQQueueBuildup",
+			fmt.Sprintf(`sum by (pod) (rate(vernemq_queue_message_in{%s}[5m]) - rate(vernemq_queue_message_out{%s}[5m]) - rate(vernemq_queue_message_drop{%s}[5m]) - rate(vernemq_queue_message_expired{%s}[5m])) > %d`, selector, selector, selector, selector, queueGrowthRate),
+			fmt.Sprintf("the queues of VerneMQ %s/%s grow on {{ $labels.pod }}", instance.Namespace, instance.Name)),
+		rule("VerneMQMessagesDropped",
+			fmt.Sprintf(`sum by (pod) (rate(vernemq_queue_message_drop{%s}[5m])) > 0`, selector),
+			fmt.Sprintf("VerneMQ %s/%s drops messages on {{ $labels.pod }}", instance.Namespace, instance.Name)),
+		rule("VerneMQClusterSizeMismatch",
+			fmt.Sprintf(`count(up{%s} == 1) != %d or absent(up{%s} == 1)`, selector, size, selector),
+			fmt.Sprintf("VerneMQ %s/%s doesn't run %d nodes", instance.Namespace, instance.Name, size)),
+	}
+	return makeMonitoringObject(instance, prometheusRuleKind, map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  prefixedName(instance.Name),
+				"rules": rules,
+			},
+		},
+	})
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileMonitoringDeletesOwnedObjects(t *testing.T) {
+	instance := &vernemqv1alpha1.VerneMQ{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "vmq.k8s.vernemq.com/v1alpha1", Kind: "VerneMQ"},
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "messaging", UID: "broker-uid"},
+	}
+	owned := monitoringObject(instance, serviceMonitorKind)
+	foreign := monitoringObject(instance, podMonitorKind)
+	foreign.SetOwnerReferences(nil)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(serviceMonitorKind, meta.RESTScopeNamespace)
+	mapper.Add(podMonitorKind, meta.RESTScopeNamespace)
+	r := &ReconcileVerneMQ{
+		client: fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithRESTMapper(mapper).WithObjects(owned, foreign).Build(),
+	}
+	if err := r.reconcileMonitoring(context.Background(), instance); err != nil {
+		t.Fatalf("reconcileMonitoring() error = %v", err)
+	}
+
+	key := types.NamespacedName{Name: prefixedName(instance.Name), Namespace: instance.Namespace}
+	for _, tt := range []struct {
+		obj         *unstructured.Unstructured
+		wantDeleted bool
+	}{
+		{owned, true},
+		{foreign, false},
+	} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(tt.obj.GroupVersionKind())
+		err := r.client.Get(context.Background(), key, obj)
+		if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+			t.Errorf("%s deleted = %v, want %v (error %v)", tt.obj.GetKind(), deleted, tt.wantDeleted, err)
+		}
+	}
+}
+
+// monitoringObject returns the object of the kind as created for the instance
+func monitoringObject(instance *vernemqv1alpha1.VerneMQ, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	withMonitoring := instance.DeepCopy()
+	withMonitoring.Spec.Monitoring = &vernemqv1alpha1.MonitoringSpec{}
+	return makeMonitoringObject(withMonitoring, gvk, map[string]interface{}{})
+}
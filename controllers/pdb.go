@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"fmt"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func pdbName(name string) string {
+	return prefixedName(name)
+}
+
+func bundlerPDBName(name string) string {
+	return fmt.Sprintf("%s-bundler", prefixedName(name))
+}
+
+// pdbDisabled reports whether the PodDisruptionBudgets of the instance are removed
+func pdbDisabled(instance *vernemqv1alpha1.VerneMQ) bool {
+	return instance.Spec.PodDisruptionBudget != nil && instance.Spec.PodDisruptionBudget.Disabled
+}
+
+// rolloutInProgress reports whether the StatefulSet is rolling out a new revision
+func rolloutInProgress(statefulset *appsv1.StatefulSet) bool {
+	return statefulset.Status.UpdateRevision != statefulset.Status.CurrentRevision ||
+		statefulset.Status.UpdatedReplicas < statefulset.Status.Replicas
+}
+
+// makePodDisruptionBudget returns the PodDisruptionBudget of the VerneMQ pods.
+// Voluntary disruptions are blocked while the StatefulSet rolls or the cluster
+// is partitioned.
+func makePodDisruptionBudget(instance *vernemqv1alpha1.VerneMQ, statefulset *appsv1.StatefulSet, partitioned bool) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(1)
+	if instance.Spec.PodDisruptionBudget != nil && instance.Spec.PodDisruptionBudget.MaxUnavailable != nil {
+		maxUnavailable = *instance.Spec.PodDisruptionBudget.MaxUnavailable
+	}
+	if rolloutInProgress(statefulset) || partitioned {
+		maxUnavailable = intstr.FromInt(0)
+	}
+
+	return makePDB(instance, pdbName(instance.Name), labelsForVerneMQ(instance.Name), maxUnavailable)
+}
+
+// makeBundlerPodDisruptionBudget keeps a bundler pod serving bundles during drains.
+// It doesn't block the eviction of a single replica.
+func makeBundlerPodDisruptionBudget(instance *vernemqv1alpha1.VerneMQ) *policyv1.PodDisruptionBudget {
+	return makePDB(instance, bundlerPDBName(instance.Name), bundlerPodLabels(instance.Name), intstr.FromInt(1))
+}
+
+func makePDB(instance *vernemqv1alpha1.VerneMQ, name string, selector map[string]string, maxUnavailable intstr.IntOrString) *policyv1.PodDisruptionBudget {
+	boolTrue := true
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    labelsForVerneMQ(instance.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         instance.APIVersion,
+					BlockOwnerDeletion: &boolTrue,
+					Controller:         &boolTrue,
+					Kind:               instance.Kind,
+					Name:               instance.Name,
+					UID:                instance.UID,
+				},
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+		},
+	}
+}
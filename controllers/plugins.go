@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// selectedPlugins lists the VerneMQPlugins selected by the instance, ordered by name
+func (r *ReconcileVerneMQ) selectedPlugins(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) ([]vernemqv1alpha1.VerneMQPlugin, error) {
+	plugins := &vernemqv1alpha1.VerneMQPluginList{}
+	if err := r.listSelected(ctx, instance.Namespace, instance.Spec.PluginSelector, plugins); err != nil {
+		return nil, pkgerr.Wrap(err, "list plugins")
+	}
+	sort.Slice(plugins.Items, func(i, j int) bool { return plugins.Items[i].Name < plugins.Items[j].Name })
+	return plugins.Items, nil
+}
+
+// listSelected lists the objects in the namespace matching the selector. Nothing
+// is selected by a nil selector.
+func (r *ReconcileVerneMQ) listSelected(ctx context.Context, namespace string, labelSelector *metav1.LabelSelector, list client.ObjectList) error {
+	if labelSelector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return pkgerr.Wrap(err, "invalid selector")
+	}
+	return r.client.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+}
+
+// mergePlugins adds the sources, plugins and config items of the VerneMQPlugins
+// to the spec of the instance. Plugins already defined in the spec must not be
+// declared by a VerneMQPlugin as well.
+func mergePlugins(instance *vernemqv1alpha1.VerneMQ, plugins []vernemqv1alpha1.VerneMQPlugin) error {
+	declared := map[string]string{}
+	for _, p := range instance.Spec.ExternalPlugins {
+		declared[p.ApplicationName] = "spec.externalPlugins"
+	}
+	for _, p := range instance.Spec.Config.Plugins {
+		declared[p.Name] = "spec.config.plugins"
+	}
+	configs := map[string]string{}
+	for _, c := range instance.Spec.Config.Configs {
+		configs[c.Name] = "spec.config.configs"
+	}
+
+	for _, plugin := range plugins {
+		owner := fmt.Sprintf("VerneMQPlugin %s", plugin.Name)
+		if other, ok := declared[plugin.Spec.Name]; ok {
+			return fmt.Errorf("plugin %s of %s is already declared by %s", plugin.Spec.Name, owner, other)
+		}
+		declared[plugin.Spec.Name] = owner
+		if plugin.Spec.Source != nil {
+			instance.Spec.ExternalPlugins = append(instance.Spec.ExternalPlugins, vernemqv1alpha1.PluginSource{
+				ApplicationName:  plugin.Spec.Name,
+				PluginSourceSpec: *plugin.Spec.Source,
+			})
+		}
+		instance.Spec.Config.Plugins = append(instance.Spec.Config.Plugins, plugin.Spec.Plugin)
+		for _, c := range plugin.Spec.Configs {
+			if other, ok := configs[c.Name]; ok {
+				return fmt.Errorf("config %s of %s is already set by %s", c.Name, owner, other)
+			}
+			configs[c.Name] = owner
+			instance.Spec.Config.Configs = append(instance.Spec.Config.Configs, c)
+		}
+	}
+	return nil
+}
+
+// verneMQsSelecting maps an object to the VerneMQ objects in its namespace
+// whose selector, as returned by selectorOf, matches it
+func verneMQsSelecting(c client.Client, selectorOf func(*vernemqv1alpha1.VerneMQ) *metav1.LabelSelector) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		instances := &vernemqv1alpha1.VerneMQList{}
+		if err := c.List(context.Background(), instances, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.Error(err, "listing VerneMQs failed", "object", obj.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range instances.Items {
+			instance := &instances.Items[i]
+			labelSelector := selectorOf(instance)
+			if labelSelector == nil {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+			if err != nil || !selector.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMergePluginsWithConfigs(t *testing.T) {
+	instance := &vernemqv1alpha1.VerneMQ{
+		Spec: vernemqv1alpha1.VerneMQSpec{
+			Version:   "1.12.3",
+			VMQConfig: "myplugin.endpoint = http://localhost:8080",
+		},
+	}
+	plugins := []vernemqv1alpha1.VerneMQPlugin{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "myplugin"},
+			Spec: vernemqv1alpha1.VerneMQPluginSpec{
+				Plugin: vernemqv1alpha1.Plugin{Name: "myplugin"},
+				Source: &vernemqv1alpha1.PluginSourceSpec{
+					RepoURL: "https://github.com/example/myplugin", VersionType: "tag", Version: "v1.0.0",
+				},
+				Configs: []vernemqv1alpha1.ConfigItem{
+					{Name: "myplugin.timeout", Value: "5000"},
+					{Name: "myplugin.pool.size", Value: "10"},
+				},
+			},
+		},
+	}
+	if err := mergePlugins(instance, plugins); err != nil {
+		t.Fatalf("mergePlugins() error = %v", err)
+	}
+	if len(instance.Spec.Config.Configs) != 2 || len(instance.Spec.ExternalPlugins) != 1 {
+		t.Fatalf("mergePlugins() didn't merge the plugin: %+v", instance.Spec)
+	}
+	if err := ValidateConfig(instance); err != nil {
+		t.Errorf("ValidateConfig() error = %v", err)
+	}
+	unknown, err := UnknownConfigKeys(instance)
+	if err != nil {
+		t.Fatalf("UnknownConfigKeys() error = %v", err)
+	}
+	if len(unknown) > 0 {
+		t.Errorf("UnknownConfigKeys() = %v, want the keys of myplugin to be skipped", unknown)
+	}
+
+	// keys of other applications are still checked
+	instance.Spec.Config.Configs = append(instance.Spec.Config.Configs, vernemqv1alpha1.ConfigItem{Name: "otherplugin.timeout", Value: "1"})
+	unknown, err = UnknownConfigKeys(instance)
+	if err != nil {
+		t.Fatalf("UnknownConfigKeys() error = %v", err)
+	}
+	if len(unknown) != 1 || unknown[0] != "otherplugin.timeout" {
+		t.Errorf("UnknownConfigKeys() = %v, want [otherplugin.timeout]", unknown)
+	}
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"fmt"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// startupFailureThreshold allows up to 10m on startup for data recovery
+	startupFailureThreshold   int32 = 120
+	livenessFailureThreshold  int32 = 60
+	readinessFailureThreshold int32 = 3
+
+	// clusterJoinedTimeoutSeconds leaves time for vmq-admin to connect to the node
+	clusterJoinedTimeoutSeconds int32 = 10
+)
+
+// queuesLoadedFile is created by the vmq_k8s plugin once the node finished loading
+// the queues of the message store. It is kept in the container filesystem, so it
+// doesn't survive a restart of the container.
+const queuesLoadedFile = "/tmp/vmq_k8s/queues-loaded"
+
+// clusterJoinedScript succeeds once vmq-admin can reach the node, which requires
+// vmq_server to be started, the node finished loading its queues and it sees a
+// majority of the nodes in the cluster view running. In clusters of up to two nodes
+// the node only needs to see itself, so that restarting one node of a two node
+// cluster doesn't make the other node unready.
+var clusterJoinedScript = fmt.Sprintf(`curl -sf http://localhost:8888/health >/dev/null || exit 1
+[ -f %s ] || exit 1
+nodes=$(vmq-admin cluster show) || exit 1
+expected=$(tr ';' '\n' < %sclusterview/vernemq.clusterview | grep -c .)
+required=$((expected / 2 + 1))
+[ "$expected" -gt 2 ] || required=1
+[ "$(echo "$nodes" | grep -c true)" -ge "$required" ]`, queuesLoadedFile, configmapsDir)
+
+// makeProbes returns the startup, liveness and readiness probes of the VerneMQ container
+func makeProbes(instance *vernemqv1alpha1.VerneMQ) (*v1.Probe, *v1.Probe, *v1.Probe) {
+	probes := instance.Spec.Probes
+	if probes == nil {
+		probes = &vernemqv1alpha1.ProbesSpec{}
+	}
+	healthHandler := v1.ProbeHandler{
+		HTTPGet: &v1.HTTPGetAction{
+			Path: "/health",
+			Port: intstr.FromInt(8888),
+		},
+	}
+
+	startupProbe := &v1.Probe{
+		ProbeHandler:     healthHandler,
+		PeriodSeconds:    5,
+		TimeoutSeconds:   probeTimeoutSeconds,
+		FailureThreshold: startupFailureThreshold,
+	}
+	livenessProbe := &v1.Probe{
+		ProbeHandler:     healthHandler,
+		PeriodSeconds:    5,
+		TimeoutSeconds:   probeTimeoutSeconds,
+		FailureThreshold: livenessFailureThreshold,
+	}
+	readinessProbe := &v1.Probe{
+		ProbeHandler:     healthHandler,
+		PeriodSeconds:    5,
+		TimeoutSeconds:   probeTimeoutSeconds,
+		FailureThreshold: readinessFailureThreshold,
+	}
+	if probes.ReadinessMode == vernemqv1alpha1.ReadinessModeClusterJoined {
+		readinessProbe.ProbeHandler = v1.ProbeHandler{
+			Exec: &v1.ExecAction{
+				Command: []string{"/bin/sh", "-c", clusterJoinedScript},
+			},
+		}
+		readinessProbe.PeriodSeconds = 10
+		readinessProbe.TimeoutSeconds = clusterJoinedTimeoutSeconds
+	}
+
+	applyProbeSettings(startupProbe, probes.Startup)
+	applyProbeSettings(livenessProbe, probes.Liveness)
+	applyProbeSettings(readinessProbe, probes.Readiness)
+	return startupProbe, livenessProbe, readinessProbe
+}
+
+func applyProbeSettings(probe *v1.Probe, settings *vernemqv1alpha1.ProbeSettings) {
+	if settings == nil {
+		return
+	}
+	if settings.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *settings.InitialDelaySeconds
+	}
+	if settings.PeriodSeconds != nil {
+		probe.PeriodSeconds = *settings.PeriodSeconds
+	}
+	if settings.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *settings.TimeoutSeconds
+	}
+	if settings.SuccessThreshold != nil {
+		probe.SuccessThreshold = *settings.SuccessThreshold
+	}
+	if settings.FailureThreshold != nil {
+		probe.FailureThreshold = *settings.FailureThreshold
+	}
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+)
+
+func TestMakeProbes(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	tests := []struct {
+		name                   string
+		probes                 *vernemqv1alpha1.ProbesSpec
+		wantStartupFailures    int32
+		wantLivenessPeriod     int32
+		wantReadinessTimeout   int32
+		wantReadinessFailures  int32
+		wantReadinessInitDelay int32
+		wantClusterJoined      bool
+	}{
+		{
+			name:                  "defaults",
+			wantStartupFailures:   startupFailureThreshold,
+			wantLivenessPeriod:    5,
+			wantReadinessTimeout:  probeTimeoutSeconds,
+			wantReadinessFailures: readinessFailureThreshold,
+		},
+		{
+			name: "overrides",
+			probes: &vernemqv1alpha1.ProbesSpec{
+				Startup:   &vernemqv1alpha1.ProbeSettings{FailureThreshold: int32Ptr(240)},
+				Liveness:  &vernemqv1alpha1.ProbeSettings{PeriodSeconds: int32Ptr(20)},
+				Readiness: &vernemqv1alpha1.ProbeSettings{InitialDelaySeconds: int32Ptr(15), FailureThreshold: int32Ptr(6)},
+			},
+			wantStartupFailures:    240,
+			wantLivenessPeriod:     20,
+			wantReadinessTimeout:   probeTimeoutSeconds,
+			wantReadinessFailures:  6,
+			wantReadinessInitDelay: 15,
+		},
+		{
+			name:                  "cluster joined",
+			probes:                &vernemqv1alpha1.ProbesSpec{ReadinessMode: vernemqv1alpha1.ReadinessModeClusterJoined},
+			wantStartupFailures:   startupFailureThreshold,
+			wantLivenessPeriod:    5,
+			wantReadinessTimeout:  clusterJoinedTimeoutSeconds,
+			wantReadinessFailures: readinessFailureThreshold,
+			wantClusterJoined:     true,
+		},
+		{
+			name: "cluster joined with timeout",
+			probes: &vernemqv1alpha1.ProbesSpec{
+				ReadinessMode: vernemqv1alpha1.ReadinessModeClusterJoined,
+				Readiness:     &vernemqv1alpha1.ProbeSettings{TimeoutSeconds: int32Ptr(30)},
+			},
+			wantStartupFailures:   startupFailureThreshold,
+			wantLivenessPeriod:    5,
+			wantReadinessTimeout:  30,
+			wantReadinessFailures: readinessFailureThreshold,
+			wantClusterJoined:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{Spec: vernemqv1alpha1.VerneMQSpec{Probes: tt.probes}}
+			startup, liveness, readiness := makeProbes(instance)
+			if startup.HTTPGet == nil || liveness.HTTPGet == nil {
+				t.Fatalf("makeProbes() startup and liveness probes must check /health")
+			}
+			if startup.FailureThreshold != tt.wantStartupFailures {
+				t.Errorf("startup failureThreshold = %d, want %d", startup.FailureThreshold, tt.wantStartupFailures)
+			}
+			if liveness.PeriodSeconds != tt.wantLivenessPeriod {
+				t.Errorf("liveness periodSeconds = %d, want %d", liveness.PeriodSeconds, tt.wantLivenessPeriod)
+			}
+			if readiness.TimeoutSeconds != tt.wantReadinessTimeout {
+				t.Errorf("readiness timeoutSeconds = %d, want %d", readiness.TimeoutSeconds, tt.wantReadinessTimeout)
+			}
+			if readiness.FailureThreshold != tt.wantReadinessFailures {
+				t.Errorf("readiness failureThreshold = %d, want %d", readiness.FailureThreshold, tt.wantReadinessFailures)
+			}
+			if readiness.InitialDelaySeconds != tt.wantReadinessInitDelay {
+				t.Errorf("readiness initialDelaySeconds = %d, want %d", readiness.InitialDelaySeconds, tt.wantReadinessInitDelay)
+			}
+			if clusterJoined := readiness.Exec != nil; clusterJoined != tt.wantClusterJoined {
+				t.Errorf("readiness probe runs the cluster joined script = %v, want %v", clusterJoined, tt.wantClusterJoined)
+			}
+			if tt.wantClusterJoined && readiness.HTTPGet != nil {
+				t.Errorf("readiness probe has more than one handler")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"path"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+)
+
+// secretFilePrefix marks the vernemq.conf values that are read from a file when
+// the VerneMQ container starts, so that credentials are neither part of the
+// VerneMQ object nor of the pod spec
+const secretFilePrefix = "@file:"
+
+// substituteSecretFilesScript copies vernemq.conf from stdin to stdout, replacing
+// the values marked with secretFilePrefix by the first line of the referenced file.
+// The substituted values are written as they are and never evaluated by the shell.
+const substituteSecretFilesScript = `while IFS= read -r line; do
+		case "$line" in
+		*" = ` + secretFilePrefix + `"*)
+			file="${line#* = ` + secretFilePrefix + `}"
+			if [ ! -r "$file" ]; then
+				echo "can't read $file referenced by vernemq.conf" >&2
+				exit 1
+			fi
+			printf '%s = %s\n' "${line%% = ` + secretFilePrefix + `*}" "$(head -n 1 "$file")"
+			;;
+		*)
+			printf '%s\n' "$line"
+			;;
+		esac
+	done`
+
+// secretFileValue returns the vernemq.conf value reading the key of a Secret
+// mounted below secretsDir
+func secretFileValue(secret string, key string) string {
+	return secretFilePrefix + path.Join(secretsDir, secret, key)
+}
+
+// mountedSecrets lists the Secrets mounted below secretsDir: the Secrets of the
+// spec, including those added by the merged bridges, and the vmq_diversity credentials
+func mountedSecrets(instance *vernemqv1alpha1.VerneMQ) []string {
+	secrets := append([]string{}, instance.Spec.Secrets...)
+	if instance.Spec.Auth != nil && instance.Spec.Auth.Diversity != nil {
+		if ref := instance.Spec.Auth.Diversity.CredentialsSecret; ref != nil && !containsString(secrets, ref.Name) {
+			secrets = append(secrets, ref.Name)
+		}
+	}
+	return secrets
+}
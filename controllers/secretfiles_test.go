@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSubstituteSecretFiles(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	password := filepath.Join(dir, "password")
+	if err := os.WriteFile(password, []byte("p@ss $HOME \"`id`\"\nsecond line\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		conf    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "values without the prefix are kept",
+			conf: "listener.tcp.default = 0.0.0.0:1883\n## comment\n\nplugins.vmq_bridge = on\n",
+			want: "listener.tcp.default = 0.0.0.0:1883\n## comment\n\nplugins.vmq_bridge = on\n",
+		},
+		{
+			name: "the first line of the file is written as it is",
+			conf: "vmq_diversity.postgres.password = " + secretFilePrefix + password + "\nallow_anonymous = off\n",
+			want: "vmq_diversity.postgres.password = p@ss $HOME \"`id`\"\nallow_anonymous = off\n",
+		},
+		{
+			name:    "missing files fail",
+			conf:    "vmq_diversity.postgres.password = " + secretFilePrefix + filepath.Join(dir, "missing") + "\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", substituteSecretFilesScript)
+			cmd.Stdin = strings.NewReader(tt.conf)
+			got, err := cmd.Output()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("substituteSecretFilesScript error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("substituteSecretFilesScript = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
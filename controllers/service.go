@@ -22,15 +22,24 @@ func makeStatefulSetService(instance *vernemqv1alpha1.VerneMQ) *v1.Service {
 			},
 			Labels: map[string]string{
 				"operated-vernemq": "true",
+				"vernemq":          instance.Name,
 			},
 		},
 		Spec: v1.ServiceSpec{
 			ClusterIP: "None",
-			Selector: map[string]string{
-				"app": "vernemq",
-			},
+			Selector:  labelsForVerneMQ(instance.Name),
 		},
 	}
+	if instance.Spec.Monitoring != nil {
+		// the metrics are served by the HTTP listener
+		svc.Spec.Ports = []v1.ServicePort{
+			{
+				Name:       metricsPortName,
+				Port:       8888,
+				TargetPort: intstr.FromString("http"),
+			},
+		}
+	}
 	svc.Name = serviceName(instance.Name)
 	svc.Namespace = instance.Namespace
 	return svc
@@ -60,9 +69,7 @@ func makeDeploymentService(instance *vernemqv1alpha1.VerneMQ) *v1.Service {
 					TargetPort: intstr.FromString("http"),
 				},
 			},
-			Selector: map[string]string{
-				"app": "vmq-bundler",
-			},
+			Selector: bundlerPodLabels(instance.Name),
 		},
 	}
 	svc.Name = serviceName(instance.Name + "-vmq-bundler")
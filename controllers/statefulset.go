@@ -11,11 +11,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// statefulSetForVerneMQ returns a VerneMQ StatefulSet object
-func makeStatefulSet(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet, error) {
+// statefulSetForVerneMQ returns a VerneMQ StatefulSet object. The inputHash is
+// added to the pod template to roll the pods if the static config changes.
+func makeStatefulSet(instance *vernemqv1alpha1.VerneMQ, inputHash string) (*appsv1.StatefulSet, error) {
 
 	// instance is passed in by value, not by reference. But p contains references like
 	// to annotation map, that do not get copied on function invocation. Ensure to
@@ -45,12 +45,17 @@ func makeStatefulSet(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet, er
 		return nil, pkgerr.Wrap(err, "make StatefulSet spec")
 	}
 
+	annotations := map[string]string{}
+	for k, v := range instance.ObjectMeta.Annotations {
+		annotations[k] = v
+	}
+
 	boolTrue := true
 	statefulset := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        prefixedName(instance.Name),
 			Namespace:   instance.Namespace,
-			Annotations: instance.ObjectMeta.Annotations,
+			Annotations: annotations,
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion:         instance.APIVersion,
@@ -65,14 +70,18 @@ func makeStatefulSet(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet, er
 		Spec: *spec,
 	}
 
-	//if statefulset.ObjectMeta.Annotations == nil {
-	//	statefulset.ObjectMeta.Annotations = map[string]string{
-	//		sSetInputHashName: inputHash,
-	//	}
-	//} else {
-	//	statefulset.ObjectMeta.Annotations[sSetInputHashName] = inputHash
-
-	//}
+	statefulset.Spec.Template.ObjectMeta.Annotations[sSetInputHashName] = inputHash
+	if instance.Spec.PluginBundle == nil {
+		if instance.Status.Bundle != nil && instance.Status.Bundle.Digest != "" {
+			// only updated by successful builds, which rolls the pods once the bundle
+			// of changed plugins is built
+			statefulset.Spec.Template.ObjectMeta.Annotations[sSetBundleDigestName] = instance.Status.Bundle.Digest
+		} else {
+			// bundlers without /status don't publish digests, the pods are rolled
+			// as soon as the plugins change
+			statefulset.Spec.Template.ObjectMeta.Annotations[sSetPluginsHashName] = pluginsHash(instance)
+		}
+	}
 
 	if instance.Spec.ImagePullSecrets != nil && len(instance.Spec.ImagePullSecrets) > 0 {
 		statefulset.Spec.Template.Spec.ImagePullSecrets = instance.Spec.ImagePullSecrets
@@ -127,9 +136,7 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 	}
 
 	vernemqCommand := []string{"/bin/sh", "-c", `
-	mkdir -p plugins && \
-	curl -L http://$VMQ_BUNDLER_HOST/bundle.tar.gz | tar xvz -C plugins && \
-	eval "echo \"$(echo $VERNEMQ_CONF | base64 -d)\"" > /vernemq/etc/vernemq.conf && \
+	eval "echo \"$(echo $VERNEMQ_CONF | base64 -d)\"" | ` + substituteSecretFilesScript + ` > /vernemq/etc/vernemq.conf && \
 	eval "echo \"$(echo $VM_ARGS | base64 -d)\"" > /vernemq/etc/vm.args && \
 	/vernemq/bin/vernemq console -noshell -noinput`}
 
@@ -155,6 +162,11 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		return nil, pkgerr.Errorf("unsupported VerneMQ major version %s", version)
 	}
 
+	vernemqConf, err := makeGlobalVerneMQConf(instance)
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "make vernemq.conf")
+	}
+
 	var securityContext *v1.PodSecurityContext
 	if instance.Spec.SecurityContext != nil {
 		securityContext = instance.Spec.SecurityContext
@@ -204,14 +216,10 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		{
 			Name: "vernemq-yaml",
 			VolumeSource: v1.VolumeSource{
+				// config.yaml and the files read by its commands are
+				// updated together
 				Secret: &v1.SecretVolumeSource{
-					SecretName: "vernemq-yaml",
-					Items: []v1.KeyToPath{
-						{
-							Key:  "config.yaml",
-							Path: "config.yaml",
-						},
-					},
+					SecretName: configSecretName,
 				},
 			},
 		},
@@ -229,6 +237,12 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 				},
 			},
 		},
+		{
+			Name: pluginsVolumeName,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		},
 	}
 
 	volName := volumeName(instance.Name)
@@ -238,11 +252,16 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		}
 	}
 
+	dataVolumeMount := v1.VolumeMount{
+		Name:      volName,
+		MountPath: storageDir,
+		SubPath:   subPathForStorage(instance.Spec.Storage),
+	}
 	vernemqVolumeMounts := []v1.VolumeMount{
+		dataVolumeMount,
 		{
-			Name:      volName,
-			MountPath: storageDir,
-			SubPath:   subPathForStorage(instance.Spec.Storage),
+			Name:      pluginsVolumeName,
+			MountPath: pluginsDir,
 		},
 		{
 			Name:      "vernemq-yaml",
@@ -254,7 +273,7 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		},
 	}
 
-	for _, s := range instance.Spec.Secrets {
+	for _, s := range mountedSecrets(instance) {
 		volumes = append(volumes, v1.Volume{
 			Name: volumeName("secret-" + s),
 			VolumeSource: v1.VolumeSource{
@@ -270,6 +289,26 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		})
 	}
 
+	if instance.Spec.Auth != nil {
+		volumes = append(volumes, v1.Volume{
+			Name: authVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: authSecretName(instance.Name),
+				},
+			},
+		})
+		vernemqVolumeMounts = append(vernemqVolumeMounts, v1.VolumeMount{
+			Name:      authVolumeName,
+			ReadOnly:  true,
+			MountPath: authDir,
+		})
+	}
+
+	diversityVolumes, diversityVolumeMounts := makeDiversityScriptsVolume(instance)
+	volumes = append(volumes, diversityVolumes...)
+	vernemqVolumeMounts = append(vernemqVolumeMounts, diversityVolumeMounts...)
+
 	for _, c := range instance.Spec.ConfigMaps {
 		volumes = append(volumes, v1.Volume{
 			Name: volumeName("configmap-" + c),
@@ -288,27 +327,7 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		})
 	}
 
-	var livenessFailureThreshold int32 = 60
-
-	var probeHandler = v1.ProbeHandler{
-		HTTPGet: &v1.HTTPGetAction{
-			Path: "/health",
-			Port: intstr.FromInt(8888),
-		},
-	}
-
-	var livenessProbe = &v1.Probe{
-		ProbeHandler:     probeHandler,
-		PeriodSeconds:    5,
-		TimeoutSeconds:   probeTimeoutSeconds,
-		FailureThreshold: livenessFailureThreshold,
-	}
-	var readinessProbe = &v1.Probe{
-		ProbeHandler:     probeHandler,
-		PeriodSeconds:    5,
-		TimeoutSeconds:   probeTimeoutSeconds,
-		FailureThreshold: 120, // allow up to 10m on startup for data recovery
-	}
+	startupProbe, livenessProbe, readinessProbe := makeProbes(instance)
 
 	podLabels := map[string]string{}
 	podAnnotations := map[string]string{}
@@ -343,9 +362,13 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 		RunAsUser:  &UID,
 		RunAsGroup: &UID,
 	}
+	pluginsContainer := makeFetchPluginsContainer(instance, vernemqImage, dataVolumeMount, &vmqContainerSecurityContext)
+	if instance.Spec.PluginBundle != nil {
+		pluginsContainer = makeCopyPluginsContainer(instance.Spec.PluginBundle, &vmqContainerSecurityContext)
+	}
 
 	additionalContainers := instance.Spec.Containers
-	envVars := instance.Spec.Env
+	envVars := append(schedulersEnv(instance), instance.Spec.Env...)
 
 	return &appsv1.StatefulSetSpec{
 		ServiceName:         serviceName(instance.Name),
@@ -363,6 +386,7 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 				Annotations: podAnnotations,
 			},
 			Spec: v1.PodSpec{
+				InitContainers: []v1.Container{pluginsContainer},
 				Containers: append([]v1.Container{
 					{
 						Name:            vernemqName,
@@ -370,6 +394,7 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 						Ports:           ports,
 						Command:         vernemqCommand,
 						VolumeMounts:    vernemqVolumeMounts,
+						StartupProbe:    startupProbe,
 						LivenessProbe:   livenessProbe,
 						ReadinessProbe:  readinessProbe,
 						Resources:       instance.Spec.Resources,
@@ -403,26 +428,17 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 								Value: fmt.Sprintf("%s/clusterview/vernemq.clusterview", configmapsDir),
 							},
 							{
-								Name:  "VMQ_BUNDLER_HOST",
-								Value: bundlerServiceName(instance.Name),
+								Name:  "VMQ_CONFIG_STATUS",
+								Value: configStatusName(instance.Name),
 							},
 							{
 								Name:  "VERNEMQ_CONF",
-								Value: makeGlobalVerneMQConf(instance),
+								Value: vernemqConf,
 							},
 							{
 								Name:  "VM_ARGS",
 								Value: makeGlobalVMArgs(instance),
 							},
-							{
-								Name: "ERLANG_SCHEDULERS",
-								ValueFrom: &v1.EnvVarSource{
-									ResourceFieldRef: &v1.ResourceFieldSelector{
-										ContainerName: vernemqName,
-										Resource:      "requests.cpu",
-									},
-								},
-							},
 							{
 								Name: "MY_POD_IP",
 								ValueFrom: &v1.EnvVarSource{
@@ -441,44 +457,22 @@ func makeStatefulSetSpec(instance *vernemqv1alpha1.VerneMQ) (*appsv1.StatefulSet
 				TerminationGracePeriodSeconds: &terminationGracePeriod,
 				Volumes:                       volumes,
 				Tolerations:                   instance.Spec.Tolerations,
-				Affinity:                      instance.Spec.Affinity,
+				Affinity:                      makeAffinity(instance),
+				TopologySpreadConstraints:     makeTopologySpreadConstraints(instance),
 			},
 		},
 	}, nil
 }
 
-func makeGlobalVerneMQConf(instance *vernemqv1alpha1.VerneMQ) string {
-	// Static configuration that can't be changed on runtime
-	// belongs here:
-	config := `metadata_plugin = vmq_swc
-listener.vmq.clustering = $MY_POD_IP:44053
-listener.http.default = 0.0.0.0:8888
-plugins.vmq_passwd = off
-plugins.vmq_acl = off
-plugins.vmq_k8s.path = /vernemq/plugins/_build/default
-plugins.vmq_k8s = on
-leveldb.maximum_memory.percent = 20
-log.console = console
-`
-	config = config + instance.Spec.VMQConfig + "\n"
-	fmt.Printf(config)
-	return base64.StdEncoding.EncodeToString([]byte(config))
+func makeGlobalVerneMQConf(instance *vernemqv1alpha1.VerneMQ) (string, error) {
+	config, err := makeVerneMQConf(instance)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(config)), nil
 }
+
 func makeGlobalVMArgs(instance *vernemqv1alpha1.VerneMQ) string {
-	// -name is added by start script
-	vmArgs := `+P 256000
--env ERL_MAX_ETS_TABLES 256000
--env ERL_CRASH_DUMP /vernemq/log/erl_crash.dump
--env ERL_FULLSWEEP_AFTER 0
--env ERL_MAX_PORTS 262144
-+A 64
--setcookie ${VMQ_DISTRIBUTED_COOKIE:-vmq}
--name vmq@$VMQ_NODENAME.$VMQ_HOSTNAME
-+K true
-+W w
--smp enable
-`
-	vmArgs = vmArgs + instance.Spec.VMArgs + "\n"
-	fmt.Printf(vmArgs)
+	vmArgs := makeVMArgs(instance)
 	return base64.StdEncoding.EncodeToString([]byte(vmArgs))
 }
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// selectedTenants lists the VerneMQTenants referencing the instance, ordered by name
+func (r *ReconcileVerneMQ) selectedTenants(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) ([]vernemqv1alpha1.VerneMQTenant, error) {
+	list := &vernemqv1alpha1.VerneMQTenantList{}
+	if err := r.client.List(ctx, list, client.InNamespace(instance.Namespace)); err != nil {
+		return nil, pkgerr.Wrap(err, "list tenants")
+	}
+	var tenants []vernemqv1alpha1.VerneMQTenant
+	for _, t := range list.Items {
+		if t.Spec.VerneMQ == instance.Name {
+			tenants = append(tenants, t)
+		}
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Name < tenants[j].Name })
+	return tenants, nil
+}
+
+// acceptTenants returns the tenants that can be merged into the instance, ordered
+// by name, and the reason each other tenant is skipped for by its name. A mountpoint
+// must not be claimed by more than one tenant and a port must not be used by more
+// than one listener. Of conflicting tenants the oldest one is accepted.
+func acceptTenants(instance *vernemqv1alpha1.VerneMQ, tenants []vernemqv1alpha1.VerneMQTenant) ([]vernemqv1alpha1.VerneMQTenant, map[string]string) {
+	ports := map[int]string{}
+	for _, l := range instance.Spec.Config.Listeners {
+		ports[l.Port] = "spec.config.listeners"
+	}
+	mountpoints := map[string]string{}
+
+	byAge := append([]vernemqv1alpha1.VerneMQTenant{}, tenants...)
+	sort.SliceStable(byAge, func(i, j int) bool {
+		if !byAge[i].CreationTimestamp.Equal(&byAge[j].CreationTimestamp) {
+			return byAge[i].CreationTimestamp.Before(&byAge[j].CreationTimestamp)
+		}
+		return byAge[i].Name < byAge[j].Name
+	})
+	rejected := map[string]string{}
+	for _, tenant := range byAge {
+		if err := checkTenant(tenant, mountpoints, ports); err != nil {
+			rejected[tenant.Name] = err.Error()
+			continue
+		}
+		owner := fmt.Sprintf("VerneMQTenant %s", tenant.Name)
+		mountpoints[tenant.Spec.Mountpoint] = owner
+		for _, l := range tenant.Spec.Listeners {
+			ports[l.Port] = owner
+		}
+	}
+	var accepted []vernemqv1alpha1.VerneMQTenant
+	for _, tenant := range tenants {
+		if _, ok := rejected[tenant.Name]; !ok {
+			accepted = append(accepted, tenant)
+		}
+	}
+	return accepted, rejected
+}
+
+// checkTenant checks the tenant against the mountpoints and ports already in use
+func checkTenant(tenant vernemqv1alpha1.VerneMQTenant, mountpoints map[string]string, ports map[int]string) error {
+	if other, ok := mountpoints[tenant.Spec.Mountpoint]; ok {
+		return fmt.Errorf("mountpoint %s is already claimed by %s", tenant.Spec.Mountpoint, other)
+	}
+	seen := map[int]bool{}
+	for _, l := range tenant.Spec.Listeners {
+		if l.Mountpoint != "" && l.Mountpoint != tenant.Spec.Mountpoint {
+			return fmt.Errorf("listener on port %d must not set another mountpoint", l.Port)
+		}
+		if other, ok := ports[l.Port]; ok {
+			return fmt.Errorf("port %d is already used by %s", l.Port, other)
+		}
+		if seen[l.Port] {
+			return fmt.Errorf("port %d is used by more than one listener", l.Port)
+		}
+		seen[l.Port] = true
+	}
+	return nil
+}
+
+// mergeTenants adds the listeners of the accepted tenants to the reloadable config
+// of the instance
+func mergeTenants(instance *vernemqv1alpha1.VerneMQ, tenants []vernemqv1alpha1.VerneMQTenant) {
+	for _, tenant := range tenants {
+		for _, l := range tenant.Spec.Listeners {
+			l.Mountpoint = tenant.Spec.Mountpoint
+			if l.MaxConnections == 0 {
+				l.MaxConnections = tenant.Spec.MaxConnections
+			}
+			instance.Spec.Config.Listeners = append(instance.Spec.Config.Listeners, l)
+		}
+	}
+}
+
+// updateTenantStatus records in the status of the tenants whether they are merged
+func (r *ReconcileVerneMQ) updateTenantStatus(ctx context.Context, tenants []vernemqv1alpha1.VerneMQTenant, rejected map[string]string) error {
+	for i := range tenants {
+		tenant := &tenants[i]
+		observed := tenant.Status.DeepCopy()
+		condition := metav1.Condition{
+			Type:               vernemqv1alpha1.ConditionTenantAccepted,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: tenant.Generation,
+			Reason:             "Accepted",
+		}
+		if reason, ok := rejected[tenant.Name]; ok {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "Conflict"
+			condition.Message = reason
+		}
+		meta.SetStatusCondition(&tenant.Status.Conditions, condition)
+		if reflect.DeepEqual(*observed, tenant.Status) {
+			continue
+		}
+		if err := r.client.Status().Update(ctx, tenant); err != nil {
+			return pkgerr.Wrapf(err, "update status of tenant %s", tenant.Name)
+		}
+	}
+	return nil
+}
+
+// tenantAuth lists the users and ACLs selected by the tenants. The ACLs are
+// moved to the mountpoint of their tenant.
+func (r *ReconcileVerneMQ) tenantAuth(ctx context.Context, instance *vernemqv1alpha1.VerneMQ, tenants []vernemqv1alpha1.VerneMQTenant) ([]vernemqv1alpha1.VerneMQUser, []vernemqv1alpha1.VerneMQACL, error) {
+	var users []vernemqv1alpha1.VerneMQUser
+	var acls []vernemqv1alpha1.VerneMQACL
+	for _, tenant := range tenants {
+		tenantUsers := &vernemqv1alpha1.VerneMQUserList{}
+		if err := r.listSelected(ctx, instance.Namespace, tenant.Spec.UserSelector, tenantUsers); err != nil {
+			return nil, nil, pkgerr.Wrapf(err, "list users of tenant %s", tenant.Name)
+		}
+		users = append(users, tenantUsers.Items...)
+
+		tenantACLs := &vernemqv1alpha1.VerneMQACLList{}
+		if err := r.listSelected(ctx, instance.Namespace, tenant.Spec.ACLSelector, tenantACLs); err != nil {
+			return nil, nil, pkgerr.Wrapf(err, "list ACLs of tenant %s", tenant.Name)
+		}
+		for _, acl := range tenantACLs.Items {
+			acl.Spec.Mountpoint = tenant.Spec.Mountpoint
+			acls = append(acls, acl)
+		}
+	}
+	return users, acls, nil
+}
+
+// verneMQOfTenant maps a VerneMQTenant to the VerneMQ object it references
+func verneMQOfTenant(obj client.Object) []reconcile.Request {
+	tenant, ok := obj.(*vernemqv1alpha1.VerneMQTenant)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: tenant.Spec.VerneMQ, Namespace: tenant.Namespace}},
+	}
+}
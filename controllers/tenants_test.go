@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAcceptTenants(t *testing.T) {
+	tenant := func(name string, age time.Duration, mountpoint string, listeners ...vernemqv1alpha1.Listener) vernemqv1alpha1.VerneMQTenant {
+		return vernemqv1alpha1.VerneMQTenant{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(time.Unix(0, 0).Add(-age))},
+			Spec:       vernemqv1alpha1.VerneMQTenantSpec{Mountpoint: mountpoint, Listeners: listeners},
+		}
+	}
+	tests := []struct {
+		name         string
+		tenants      []vernemqv1alpha1.VerneMQTenant
+		wantAccepted []string
+		wantRejected []string
+	}{
+		{
+			name:         "separate mountpoints and ports",
+			tenants:      []vernemqv1alpha1.VerneMQTenant{tenant("a", 0, "a", vernemqv1alpha1.Listener{Port: 1884}), tenant("b", 0, "b", vernemqv1alpha1.Listener{Port: 1885})},
+			wantAccepted: []string{"a", "b"},
+		},
+		{
+			name:         "the oldest tenant keeps a shared mountpoint",
+			tenants:      []vernemqv1alpha1.VerneMQTenant{tenant("a", 0, "shared"), tenant("b", time.Hour, "shared")},
+			wantAccepted: []string{"b"},
+			wantRejected: []string{"a"},
+		},
+		{
+			name:         "port of the VerneMQ object",
+			tenants:      []vernemqv1alpha1.VerneMQTenant{tenant("a", 0, "a", vernemqv1alpha1.Listener{Port: 1883}), tenant("b", 0, "b", vernemqv1alpha1.Listener{Port: 1885})},
+			wantAccepted: []string{"b"},
+			wantRejected: []string{"a"},
+		},
+		{
+			name:         "listener with another mountpoint",
+			tenants:      []vernemqv1alpha1.VerneMQTenant{tenant("a", 0, "a", vernemqv1alpha1.Listener{Port: 1884, Mountpoint: "b"})},
+			wantRejected: []string{"a"},
+		},
+		{
+			name:         "rejected tenants don't claim their ports",
+			tenants:      []vernemqv1alpha1.VerneMQTenant{tenant("a", time.Hour, "a", vernemqv1alpha1.Listener{Port: 1884}, vernemqv1alpha1.Listener{Port: 1884}), tenant("b", 0, "b", vernemqv1alpha1.Listener{Port: 1884})},
+			wantAccepted: []string{"b"},
+			wantRejected: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				Spec: vernemqv1alpha1.VerneMQSpec{Config: vernemqv1alpha1.ReloadableConfig{Listeners: []vernemqv1alpha1.Listener{{Port: 1883}}}},
+			}
+			accepted, rejected := acceptTenants(instance, tt.tenants)
+			var acceptedNames []string
+			for _, tenant := range accepted {
+				acceptedNames = append(acceptedNames, tenant.Name)
+			}
+			if !reflect.DeepEqual(acceptedNames, tt.wantAccepted) {
+				t.Errorf("acceptTenants() accepted = %v, want %v", acceptedNames, tt.wantAccepted)
+			}
+			if got := sortedKeys(rejected); !reflect.DeepEqual(got, tt.wantRejected) && len(got)+len(tt.wantRejected) > 0 {
+				t.Errorf("acceptTenants() rejected = %v, want %v", got, tt.wantRejected)
+			}
+		})
+	}
+}
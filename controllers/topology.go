@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	zoneTopologyKey = "topology.kubernetes.io/zone"
+	hostTopologyKey = "kubernetes.io/hostname"
+)
+
+// topologyKeys returns the topology keys the pods of the instance are spread across
+func topologyKeys(topology *vernemqv1alpha1.TopologySpec) []string {
+	switch topology.Spread {
+	case vernemqv1alpha1.TopologySpreadZone:
+		return []string{zoneTopologyKey}
+	case vernemqv1alpha1.TopologySpreadHost:
+		return []string{hostTopologyKey}
+	case vernemqv1alpha1.TopologySpreadZoneAndHost:
+		return []string{zoneTopologyKey, hostTopologyKey}
+	}
+	return nil
+}
+
+// makeTopologySpreadConstraints spreads the pods of the instance across the
+// domains selected by the topology
+func makeTopologySpreadConstraints(instance *vernemqv1alpha1.VerneMQ) []v1.TopologySpreadConstraint {
+	topology := instance.Spec.Topology
+	if topology == nil {
+		return nil
+	}
+	maxSkew := int32(1)
+	if topology.MaxSkew != nil {
+		maxSkew = *topology.MaxSkew
+	}
+	whenUnsatisfiable := v1.ScheduleAnyway
+	if topology.Mode == vernemqv1alpha1.TopologyModeRequired {
+		whenUnsatisfiable = v1.DoNotSchedule
+	}
+	var constraints []v1.TopologySpreadConstraint
+	for _, key := range topologyKeys(topology) {
+		constraints = append(constraints, v1.TopologySpreadConstraint{
+			MaxSkew:           maxSkew,
+			TopologyKey:       key,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: labelsForVerneMQ(instance.Name),
+			},
+		})
+	}
+	return constraints
+}
+
+// makeAffinity merges the pod anti-affinity of the topology into the affinity of
+// the instance. Pods are kept off hosts already running a pod of the instance.
+func makeAffinity(instance *vernemqv1alpha1.VerneMQ) *v1.Affinity {
+	topology := instance.Spec.Topology
+	if topology == nil || topology.Spread == vernemqv1alpha1.TopologySpreadZone {
+		return instance.Spec.Affinity
+	}
+	affinity := &v1.Affinity{}
+	if instance.Spec.Affinity != nil {
+		affinity = instance.Spec.Affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+	}
+	term := v1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: labelsForVerneMQ(instance.Name),
+		},
+		TopologyKey: hostTopologyKey,
+	}
+	antiAffinity := affinity.PodAntiAffinity
+	if topology.Mode == vernemqv1alpha1.TopologyModeRequired {
+		antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+	} else {
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, v1.WeightedPodAffinityTerm{
+			Weight:          100,
+			PodAffinityTerm: term,
+		})
+	}
+	return affinity
+}
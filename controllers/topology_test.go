@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMakeAffinity(t *testing.T) {
+	nodeAffinity := &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "pool", Operator: v1.NodeSelectorOpIn, Values: []string{"mqtt"}},
+			}}},
+		},
+	}}
+	term := v1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: labelsForVerneMQ("broker")},
+		TopologyKey:   hostTopologyKey,
+	}
+	tests := []struct {
+		name     string
+		affinity *v1.Affinity
+		topology *vernemqv1alpha1.TopologySpec
+		want     *v1.Affinity
+	}{
+		{
+			name:     "no topology",
+			affinity: nodeAffinity,
+			want:     nodeAffinity,
+		},
+		{
+			name:     "zone spread",
+			affinity: nodeAffinity,
+			topology: &vernemqv1alpha1.TopologySpec{Spread: vernemqv1alpha1.TopologySpreadZone},
+			want:     nodeAffinity,
+		},
+		{
+			name:     "preferred",
+			topology: &vernemqv1alpha1.TopologySpec{Spread: vernemqv1alpha1.TopologySpreadHost},
+			want: &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: term}},
+			}},
+		},
+		{
+			name:     "required keeps the affinity of the instance",
+			affinity: nodeAffinity,
+			topology: &vernemqv1alpha1.TopologySpec{Spread: vernemqv1alpha1.TopologySpreadZoneAndHost, Mode: vernemqv1alpha1.TopologyModeRequired},
+			want: &v1.Affinity{
+				NodeAffinity: nodeAffinity.NodeAffinity,
+				PodAntiAffinity: &v1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{term},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				ObjectMeta: metav1.ObjectMeta{Name: "broker"},
+				Spec:       vernemqv1alpha1.VerneMQSpec{Affinity: tt.affinity, Topology: tt.topology},
+			}
+			if got := makeAffinity(instance); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("makeAffinity() = %v, want %v", got, tt.want)
+			}
+			if nodeAffinity.PodAntiAffinity != nil {
+				t.Fatalf("makeAffinity() modified the affinity of the instance")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	"github.com/vernemq/vmq-operator/pkg/cuttlefish"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// ValidateConfig checks that the configuration of the instance can be rendered:
+// the plugin sources are complete, the scheduler settings are consistent and
+// vmqConfig doesn't collide with the keys set by the operator or staticConfig.
+// Keys unknown to the cuttlefish schema are checked by UnknownConfigKeys, they are
+// rejected on admission and reported by the reconciler.
+func ValidateConfig(instance *vernemqv1alpha1.VerneMQ) error {
+	if instance.Spec.PluginBundle != nil && (len(instance.Spec.ExternalPlugins) > 0 || instance.Spec.Bundler != nil) {
+		return fmt.Errorf("pluginBundle can't be combined with externalPlugins or bundler")
+	}
+	if err := validatePluginSources(bundledPlugins(instance)); err != nil {
+		return err
+	}
+	if err := validateVmqK8sSource(vmqK8sSource(instance)); err != nil {
+		return err
+	}
+	if err := validateSchedulers(instance); err != nil {
+		return err
+	}
+	if instance.Spec.Auth != nil && instance.Spec.Auth.Diversity != nil {
+		if err := validateDiversity(instance.Spec.Auth.Diversity); err != nil {
+			return err
+		}
+	}
+	_, err := makeVerneMQConf(instance)
+	return err
+}
+
+// UnknownConfigKeys returns the keys of the vernemq.conf rendered for the instance
+// and the names of the reloadable config items that are not part of the cuttlefish
+// schema of the VerneMQ version to be deployed. The embedded schemas don't cover
+// third-party plugins, so the keys loading plugins and the keys prefixed with the
+// application of a plugin are not checked. It returns
+// cuttlefish.ErrNoSchema if no schema is embedded for the version.
+func UnknownConfigKeys(instance *vernemqv1alpha1.VerneMQ) ([]string, error) {
+	version := instance.Spec.Version
+	if version == "" {
+		version = defaultVerneMQVersion
+	}
+	schema, err := cuttlefish.Lookup(version)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := makeVerneMQConf(instance)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseConfEntries(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := pluginApplications(instance, entries)
+	var keys []string
+	for _, e := range entries {
+		if !isPluginKey(plugins, e.key) {
+			keys = append(keys, e.key)
+		}
+	}
+	for _, c := range instance.Spec.Config.Configs {
+		if !isPluginKey(plugins, c.Name) {
+			keys = append(keys, c.Name)
+		}
+	}
+
+	var unknown *cuttlefish.UnknownKeysError
+	if err := schema.Validate(keys); errors.As(err, &unknown) {
+		return unknown.Keys, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// pluginApplications returns the applications of the plugins declared by the
+// instance or enabled in its vernemq.conf
+func pluginApplications(instance *vernemqv1alpha1.VerneMQ, entries []confEntry) map[string]bool {
+	plugins := map[string]bool{}
+	for _, p := range instance.Spec.ExternalPlugins {
+		plugins[p.ApplicationName] = true
+	}
+	for _, p := range instance.Spec.Config.Plugins {
+		plugins[p.Name] = true
+	}
+	for _, e := range entries {
+		if segments := strings.Split(e.key, "."); len(segments) > 1 && segments[0] == "plugins" {
+			plugins[segments[1]] = true
+		}
+	}
+	return plugins
+}
+
+// isPluginKey reports whether key configures the loading of a plugin or is owned
+// by the application of a plugin, i.e. starts with its name
+func isPluginKey(plugins map[string]bool, key string) bool {
+	prefix := strings.SplitN(key, ".", 2)[0]
+	return prefix == "plugins" || (strings.Contains(key, ".") && plugins[prefix])
+}
+
+// unknownConfigKeysCondition reports the keys returned by UnknownConfigKeys
+func unknownConfigKeysCondition(instance *vernemqv1alpha1.VerneMQ) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               vernemqv1alpha1.ConditionUnknownConfigKeys,
+		ObservedGeneration: instance.Generation,
+	}
+	keys, err := UnknownConfigKeys(instance)
+	switch {
+	case errors.Is(err, cuttlefish.ErrNoSchema):
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "SchemaNotAvailable"
+		condition.Message = err.Error()
+	case err != nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "CheckFailed"
+		condition.Message = err.Error()
+	case len(keys) > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "KeysNotInSchema"
+		condition.Message = "config keys not in the cuttlefish schema: " + strings.Join(keys, ", ")
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "AllKeysKnown"
+	}
+	return condition
+}
+
+// erlangAtomRegexp matches the application and package names that can be used
+// as unquoted atoms in rebar.config
+var erlangAtomRegexp = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
+
+// validatePluginSources checks that every external plugin has exactly one source
+// and that Git sources are complete
+func validatePluginSources(plugins []vernemqv1alpha1.PluginSource) error {
+	for _, p := range plugins {
+		if !erlangAtomRegexp.MatchString(p.ApplicationName) {
+			return fmt.Errorf("external plugin %q: applicationName must be a valid Erlang application name", p.ApplicationName)
+		}
+		if p.Hex != nil && p.Hex.Package != "" && !erlangAtomRegexp.MatchString(p.Hex.Package) {
+			return fmt.Errorf("external plugin %s: %q is not a valid hex package name", p.ApplicationName, p.Hex.Package)
+		}
+		sources := 0
+		for _, set := range []bool{p.RepoURL != "", p.Hex != nil, p.Tarball != nil, p.ConfigMap != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("external plugin %s must set exactly one of repoURL, hex, tarball and configMap", p.ApplicationName)
+		}
+		if p.RepoURL == "" {
+			if p.SecretRef != nil {
+				return fmt.Errorf("external plugin %s: secretRef is only supported for Git repositories", p.ApplicationName)
+			}
+			continue
+		}
+		switch p.VersionType {
+		case "branch", "tag", "ref", "commit":
+		default:
+			return fmt.Errorf("external plugin %s: versionType must be one of branch, tag, ref or commit", p.ApplicationName)
+		}
+		if p.Version == "" {
+			return fmt.Errorf("external plugin %s: version must be set for Git repositories", p.ApplicationName)
+		}
+	}
+	return nil
+}
+
+// validateVmqK8sSource rejects releases of vmq_k8s older than minVmqK8sVersion,
+// which don't write the node reports the operator reads. Branches, refs and tags
+// that aren't semantic versions can't be checked.
+func validateVmqK8sSource(source vernemqv1alpha1.PluginSource) error {
+	if source.VersionType != "tag" {
+		return nil
+	}
+	v, err := version.ParseSemantic(source.Version)
+	if err != nil {
+		return nil
+	}
+	if v.LessThan(version.MustParseSemantic(minVmqK8sVersion)) {
+		return fmt.Errorf("vmq_k8s %s doesn't write the node reports read by the operator, use %s or later", source.Version, minVmqK8sVersion)
+	}
+	return nil
+}
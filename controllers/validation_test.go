@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	"github.com/vernemq/vmq-operator/pkg/cuttlefish"
+)
+
+func TestUnknownConfigKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		vmqConfig string
+		configs   []vernemqv1alpha1.ConfigItem
+		want      []string
+		wantErr   error
+	}{
+		{
+			name:      "known keys",
+			version:   "1.12.3",
+			vmqConfig: "allow_anonymous = on\nlistener.tcp.default = 0.0.0.0:1883",
+		},
+		{
+			name:      "unknown keys are reported",
+			version:   "1.12.3",
+			vmqConfig: "allow_anonymous = on\nno_such_key = 1",
+			configs:   []vernemqv1alpha1.ConfigItem{{Name: "no_such_config", Value: "1"}},
+			want:      []string{"no_such_config", "no_such_key"},
+		},
+		{
+			name:      "plugin keys are not checked",
+			version:   "1.12.3",
+			vmqConfig: "plugins.myplugin = on\nplugins.myplugin.path = /plugins/myplugin",
+		},
+		{
+			name:      "default version",
+			vmqConfig: "max_message_rate = 100\nlistener.ssl.default.require_certificate = on",
+			configs:   []vernemqv1alpha1.ConfigItem{{Name: "no_such_config", Value: "1"}},
+			want:      []string{"no_such_config"},
+		},
+		{
+			name:      "no schema for the release",
+			version:   "1.11.0",
+			vmqConfig: "no_such_key = 1",
+			wantErr:   cuttlefish.ErrNoSchema,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				Spec: vernemqv1alpha1.VerneMQSpec{
+					Version:   tt.version,
+					VMQConfig: tt.vmqConfig,
+					Config:    vernemqv1alpha1.ReloadableConfig{Configs: tt.configs},
+				},
+			}
+			got, err := UnknownConfigKeys(instance)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UnknownConfigKeys() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownConfigKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePluginSources(t *testing.T) {
+	git := func(versionType string) vernemqv1alpha1.PluginSourceSpec {
+		return vernemqv1alpha1.PluginSourceSpec{RepoURL: "https://github.com/example/myplugin", VersionType: versionType, Version: "v1"}
+	}
+	tests := []struct {
+		name    string
+		source  vernemqv1alpha1.PluginSource
+		wantErr bool
+	}{
+		{name: "git tag", source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: git("tag")}},
+		{name: "git commit", source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: git("commit")}},
+		{name: "unknown version type", source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: git("sha")}, wantErr: true},
+		{name: "invalid application name", source: vernemqv1alpha1.PluginSource{ApplicationName: "my-plugin", PluginSourceSpec: git("tag")}, wantErr: true},
+		{
+			name: "invalid hex package",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				Hex: &vernemqv1alpha1.HexSource{Package: "pkg}, {evil"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "more than one source",
+			source: vernemqv1alpha1.PluginSource{ApplicationName: "myplugin", PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{
+				RepoURL: "https://github.com/example/myplugin", VersionType: "tag", Version: "v1", Hex: &vernemqv1alpha1.HexSource{},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePluginSources([]vernemqv1alpha1.PluginSource{tt.source})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePluginSources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVmqK8sSource(t *testing.T) {
+	tests := []struct {
+		versionType string
+		version     string
+		wantErr     bool
+	}{
+		{"tag", vmqK8sVersion, false},
+		{"tag", "v2.1.0", false},
+		{"tag", "v1.9.3", true},
+		{"tag", "1.0.0", true},
+		{"tag", "nightly", false},
+		{"branch", "master", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.versionType+" "+tt.version, func(t *testing.T) {
+			source := vernemqv1alpha1.PluginSource{PluginSourceSpec: vernemqv1alpha1.PluginSourceSpec{VersionType: tt.versionType, Version: tt.version}}
+			if err := validateVmqK8sSource(source); (err != nil) != tt.wantErr {
+				t.Errorf("validateVmqK8sSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
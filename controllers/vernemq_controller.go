@@ -3,8 +3,10 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
 
 	"github.com/go-logr/logr"
 	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
@@ -12,13 +14,17 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -31,21 +37,36 @@ import (
 
 // Constants for VerneMQ StatefulSet & Volumes
 const (
-	vernemqName       = "vernemq"
-	storageDir        = "/vernemq/data"
-	configmapsDir     = "/vernemq/etc/configmaps/"
-	secretsDir        = "/vernemq/etc/secrets/"
-	sSetInputHashName = "vernemq-operator-input-hash"
+	vernemqName          = "vernemq"
+	storageDir           = "/vernemq/data"
+	configmapsDir        = "/vernemq/etc/configmaps/"
+	secretsDir           = "/vernemq/etc/secrets/"
+	sSetInputHashName    = "vernemq-operator-input-hash"
+	sSetPluginsHashName  = "vernemq-operator-plugins-hash"
+	sSetBundleDigestName = "vernemq-operator-bundle-digest"
 
 	defaultVerneMQVersion   = "1.13.0-alpine"
 	defaultVerneMQBaseImage = "vernemq/vernemq"
 	defaultBundlerBaseImage = "vernemq/vmq-plugin-bundler"
 	defaultBundlerVersion   = "latest"
+
+	// rebar3CargoRef pins the rebar3_cargo plugin used to build Rust NIFs
+	rebar3CargoRef = "85353035"
+
+	// minVmqK8sVersion is the first release of the vmq_k8s plugin writing the
+	// node reports described in docs/node-reports.md
+	minVmqK8sVersion = "v2.0.0"
 )
 
 var (
+	// vmqK8sVersion is the tag of the vmq_k8s plugin matching the operator
+	// release, set with -ldflags at build time
+	vmqK8sVersion = "v2.0.0"
+
 	minSize             int32 = 1
 	probeTimeoutSeconds int32 = 3
+
+	bundlerRequestTimeout = 30 * time.Second
 )
 
 var log = logf.Log.WithName("controller_vernemq")
@@ -64,7 +85,12 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileVerneMQ{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileVerneMQ{
+		client:     mgr.GetClient(),
+		scheme:     mgr.GetScheme(),
+		httpClient: &http.Client{Timeout: bundlerRequestTimeout},
+		recorder:   mgr.GetEventRecorderFor("vernemq-controller"),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -81,6 +107,44 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to VerneMQPlugins, VerneMQUsers and VerneMQACLs and requeue the VerneMQs selecting them
+	selectors := []struct {
+		object     client.Object
+		selectorOf func(*vernemqv1alpha1.VerneMQ) *metav1.LabelSelector
+	}{
+		{&vernemqv1alpha1.VerneMQPlugin{}, func(i *vernemqv1alpha1.VerneMQ) *metav1.LabelSelector { return i.Spec.PluginSelector }},
+		{&vernemqv1alpha1.VerneMQUser{}, func(i *vernemqv1alpha1.VerneMQ) *metav1.LabelSelector {
+			if i.Spec.Auth == nil {
+				return nil
+			}
+			return i.Spec.Auth.UserSelector
+		}},
+		{&vernemqv1alpha1.VerneMQACL{}, func(i *vernemqv1alpha1.VerneMQ) *metav1.LabelSelector {
+			if i.Spec.Auth == nil {
+				return nil
+			}
+			return i.Spec.Auth.ACLSelector
+		}},
+	}
+	for _, s := range selectors {
+		err = c.Watch(&source.Kind{Type: s.object}, handler.EnqueueRequestsFromMapFunc(verneMQsSelecting(mgr.GetClient(), s.selectorOf)))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes to VerneMQBridges and requeue the VerneMQ they reference
+	err = c.Watch(&source.Kind{Type: &vernemqv1alpha1.VerneMQBridge{}}, handler.EnqueueRequestsFromMapFunc(verneMQOfBridge))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to VerneMQTenants and requeue the VerneMQ they reference
+	err = c.Watch(&source.Kind{Type: &vernemqv1alpha1.VerneMQTenant{}}, handler.EnqueueRequestsFromMapFunc(verneMQOfTenant))
+	if err != nil {
+		return err
+	}
+
 	// TODO(user): Modify this to be the types you create that are owned by the primary resource
 	// Watch for changes to secondary resource Pods and requeue the owner VerneMQ
 	err = c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}}, &handler.EnqueueRequestForOwner{
@@ -107,9 +171,11 @@ var _ reconcile.Reconciler = &ReconcileVerneMQ{}
 type ReconcileVerneMQ struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
-	logger logr.Logger
+	client     client.Client
+	scheme     *runtime.Scheme
+	logger     logr.Logger
+	httpClient *http.Client
+	recorder   record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a VerneMQ object and makes changes based on the state read
@@ -122,8 +188,22 @@ type ReconcileVerneMQ struct {
 // +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqplugins,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqusers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqacls,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqbridges,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqbridges/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqtenants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmq.k8s.vernemq.com,namespace=messaging,resources=vernemqtenants/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch
 func (r *ReconcileVerneMQ) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	r.logger = reqLogger
@@ -137,34 +217,181 @@ func (r *ReconcileVerneMQ) Reconcile(ctx context.Context, request reconcile.Requ
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			forgetInstanceMetrics(request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
 
-	deploymentService := makeDeploymentService(instance)
-	err = r.client.Create(ctx, deploymentService)
-	if err != nil && errors.IsAlreadyExists(err) == false {
-		return reconcile.Result{}, pkgerr.Wrap(err, "generating deployment service failed")
-	}
+	observedStatus := instance.Status.DeepCopy()
 
-	deployment := makeDeployment(instance)
-	err = r.createOrUpdate(ctx, deployment.Name, deployment.Namespace, deployment)
+	plugins, err := r.selectedPlugins(ctx, instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	bridges, err := r.selectedBridges(ctx, instance)
 	if err != nil {
-		return reconcile.Result{}, pkgerr.Wrap(err, "generating deployment failed")
+		return reconcile.Result{}, err
+	}
+	tenants, err := r.selectedTenants(ctx, instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// conflicting tenants are reported in their own status
+	acceptedTenants, rejectedTenants := acceptTenants(instance, tenants)
+	err = r.updateTenantStatus(ctx, tenants, rejectedTenants)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	peers, err := r.federationPeers(ctx, instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// invalid peers are reported in the federation status
+	peerBridges, rejectedPeers, err := federationBridges(instance, peers)
+	// invalid bridges are reported in their own status
+	acceptedBridges, rejectedBridges := acceptBridges(bridges, peerBridges)
+	if err == nil {
+		mergeBridges(instance, append(acceptedBridges, peerBridges...))
+		err = mergePlugins(instance, plugins)
+	}
+	var webhookFiles map[string]string
+	if err == nil {
+		mergeTenants(instance, acceptedTenants)
+		webhookFiles, err = r.mergeWebhooks(ctx, instance)
+	}
+	if err == nil {
+		err = ValidateConfig(instance)
+	}
+	if err != nil {
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:               vernemqv1alpha1.ConditionConfigInvalid,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: instance.Generation,
+			Reason:             "ValidationFailed",
+			Message:            err.Error(),
+		})
+		reqLogger.Error(err, "rejecting invalid VerneMQ config")
+		r.recorder.Event(instance, corev1.EventTypeWarning, "ConfigRejected", err.Error())
+		return reconcile.Result{}, r.updateStatus(ctx, instance, observedStatus)
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               vernemqv1alpha1.ConditionConfigInvalid,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: instance.Generation,
+		Reason:             "ValidationSucceeded",
+	})
+	meta.SetStatusCondition(&instance.Status.Conditions, unknownConfigKeysCondition(instance))
+	err = r.updateStatus(ctx, instance, observedStatus)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if instance.Spec.PluginBundle != nil {
+		// the plugins come from a prebuilt image, no bundler is needed
+		err = r.deleteBundler(ctx, instance)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "deleting bundler failed")
+		}
+		instance.Status.Bundle = nil
+	} else {
+		err = r.resolveConfigMapSources(ctx, instance)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		deploymentService := makeDeploymentService(instance)
+		err = r.createOrUpdate(ctx, instance, deploymentService.Name, deploymentService.Namespace, deploymentService)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating deployment service failed")
+		}
+
+		bundlerGitConfigMap := makeBundlerGitConfigMap(instance)
+		err = r.createOrUpdate(ctx, instance, bundlerGitConfigMap.Name, bundlerGitConfigMap.Namespace, bundlerGitConfigMap)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating bundler git config failed")
+		}
+
+		deployment := makeDeployment(instance)
+		err = r.createOrUpdate(ctx, instance, deployment.Name, deployment.Namespace, deployment)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating deployment failed")
+		}
+
+		bundlerPDB := makeBundlerPodDisruptionBudget(instance)
+		if pdbDisabled(instance) {
+			err = r.client.Delete(ctx, bundlerPDB)
+			if err != nil && errors.IsNotFound(err) == false {
+				return reconcile.Result{}, pkgerr.Wrap(err, "deleting bundler PodDisruptionBudget failed")
+			}
+		} else {
+			err = r.createOrUpdate(ctx, instance, bundlerPDB.Name, bundlerPDB.Namespace, bundlerPDB)
+			if err != nil {
+				return reconcile.Result{}, pkgerr.Wrap(err, "generating bundler PodDisruptionBudget failed")
+			}
+		}
+
+		bundlerStatus, err := r.fetchBundlerStatus(ctx, instance)
+		if err != nil {
+			// keep the last known bundle state until the bundler is reachable
+			reqLogger.Info("fetching bundler status failed", "error", err.Error())
+		} else {
+			updateBundleStatus(instance, bundlerStatus)
+			recordBundleBuild(instance, observedStatus.Bundle, bundlerStatus)
+			r.recordBundleEvents(instance, observedStatus.Bundle)
+			if err := r.recordBundlerLock(ctx, instance); err != nil {
+				reqLogger.Info("recording bundler lock failed", "error", err.Error())
+			}
+		}
+	}
+	bundleReady := bundleReadyCondition(instance)
+	meta.SetStatusCondition(&instance.Status.Conditions, bundleReady)
+
+	if instance.Spec.Auth != nil {
+		authSecret, err := r.makeAuthSecret(ctx, instance, acceptedTenants)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating auth secret failed")
+		}
+		err = r.createOrUpdate(ctx, instance, authSecret.Name, authSecret.Namespace, authSecret)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating auth secret failed")
+		}
+	}
+
+	if instance.Spec.Federation != nil {
+		memberSecret := makeFederationMemberSecret(instance)
+		err = r.createOrUpdate(ctx, instance, memberSecret.Name, memberSecret.Namespace, memberSecret)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating federation member secret failed")
+		}
 	}
 
 	service := makeStatefulSetService(instance)
-	err = r.createOrUpdate(ctx, service.Name, service.Namespace, service)
+	err = r.createOrUpdate(ctx, instance, service.Name, service.Namespace, service)
 	if err != nil {
 		return reconcile.Result{}, pkgerr.Wrap(err, "generating service failed")
 	}
-	statefulset, err := makeStatefulSet(instance)
+	err = r.reconcileMonitoring(ctx, instance)
+	if err != nil {
+		return reconcile.Result{}, pkgerr.Wrap(err, "generating monitoring failed")
+	}
+	inputHash, err := r.staticInputHash(ctx, instance)
+	if err != nil {
+		return reconcile.Result{}, pkgerr.Wrap(err, "hashing static config failed")
+	}
+	statefulset, err := makeStatefulSet(instance, inputHash)
 	if err != nil {
 		return reconcile.Result{}, pkgerr.Wrap(err, "generating statefulset failed")
 	}
-	err = r.createOrUpdate(ctx, statefulset.Name, statefulset.Namespace, statefulset)
+	// the pods keep the digest of the last built bundle until the bundle of
+	// changed plugins is built
+	bundleBlocked := bundleReady.Status == metav1.ConditionFalse
+	err = r.recordRolloutEvents(ctx, instance, statefulset)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	err = r.createOrUpdate(ctx, instance, statefulset.Name, statefulset.Namespace, statefulset)
 	if err != nil {
 		return reconcile.Result{}, pkgerr.Wrap(err, "creating statefulset failed")
 	}
@@ -175,22 +402,95 @@ func (r *ReconcileVerneMQ) Reconcile(ctx context.Context, request reconcile.Requ
 	}
 
 	// this will create config.yaml
-	configSecret := makeConfigSecretFromSpec(instance)
-	err = r.createOrUpdate(ctx, configSecret.Name, configSecret.Namespace, configSecret)
+	configSecret := makeConfigSecretFromSpec(instance, webhookFiles)
+	err = r.createOrUpdate(ctx, instance, configSecret.Name, configSecret.Namespace, configSecret)
 	if err != nil && errors.IsAlreadyExists(err) == false {
 		return reconcile.Result{}, pkgerr.Wrap(err, "creating  config Secret failed")
 	}
 
+	// the vmq_k8s plugin reports the applied config into this ConfigMap
+	configStatus := makeConfigStatusConfigMap(instance)
+	err = r.client.Create(ctx, configStatus)
+	if err != nil && errors.IsAlreadyExists(err) == false {
+		return reconcile.Result{}, pkgerr.Wrap(err, "creating config status ConfigMap failed")
+	}
+	err = r.client.Get(ctx, types.NamespacedName{Name: configStatus.Name, Namespace: configStatus.Namespace}, configStatus)
+	if err != nil {
+		return reconcile.Result{}, pkgerr.Wrap(err, "reading config status ConfigMap failed")
+	}
+	err = r.createOrUpdate(ctx, instance, configStatus.Name, instance.Namespace, makeConfigStatusRole(instance))
+	if err != nil {
+		return reconcile.Result{}, pkgerr.Wrap(err, "updating config status Role failed")
+	}
+	err = r.createOrUpdate(ctx, instance, configStatus.Name, instance.Namespace, makeConfigStatusRoleBinding(instance))
+	if err != nil {
+		return reconcile.Result{}, pkgerr.Wrap(err, "updating config status RoleBinding failed")
+	}
+	instance.Status.Nodes = getPodNames(podList.Items)
+	// the cluster view is kept while no node reports it
+	clusterViews := reportedClusterViews(configStatus, podList.Items)
+	if len(clusterViews) > 0 {
+		instance.Status.ClusterView = clusterMembers(clusterViews)
+		r.recordNodeEvents(instance, observedStatus.ClusterView)
+	}
+	instance.Status.ConfigHash = configHash(configSecret.StringData[configFileName])
+	instance.Status.ConfigStatus = makeNodeConfigStatus(configStatus, podList.Items)
+	err = r.updateBridgeStatus(ctx, bridges, rejectedBridges, configStatus, podList.Items)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	updateFederationStatus(instance, peers, rejectedPeers, configStatus, podList.Items)
+	meta.SetStatusCondition(&instance.Status.Conditions, configAppliedCondition(instance))
+	err = r.updateStatus(ctx, instance, observedStatus)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// this will create vernemq.clusterview
 	clusterViewSecret := makeClusterViewSecret(instance, podList)
-	err = r.createOrUpdate(ctx, clusterViewSecret.Name, clusterViewSecret.Namespace, clusterViewSecret)
+	err = r.createOrUpdate(ctx, instance, clusterViewSecret.Name, clusterViewSecret.Namespace, clusterViewSecret)
 	if err != nil {
 		return reconcile.Result{}, pkgerr.Wrap(err, "creating clusterview secret failed")
 	}
 
+	current := &appsv1.StatefulSet{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: statefulset.Name, Namespace: statefulset.Namespace}, current)
+	if err != nil && errors.IsNotFound(err) == false {
+		return reconcile.Result{}, pkgerr.Wrap(err, "reading statefulset failed")
+	}
+	recordClusterMetrics(instance, current, bundleBlocked)
+
+	if pdbDisabled(instance) {
+		err = r.client.Delete(ctx, &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: pdbName(instance.Name), Namespace: instance.Namespace}})
+		if err != nil && errors.IsNotFound(err) == false {
+			return reconcile.Result{}, pkgerr.Wrap(err, "deleting PodDisruptionBudget failed")
+		}
+	} else {
+		pdb := makePodDisruptionBudget(instance, current, clusterPartitioned(clusterViews))
+		err = r.createOrUpdate(ctx, instance, pdb.Name, pdb.Namespace, pdb)
+		if err != nil {
+			return reconcile.Result{}, pkgerr.Wrap(err, "generating PodDisruptionBudget failed")
+		}
+	}
+	recordSuccessfulReconcile(instance)
+
 	return reconcile.Result{Requeue: true}, nil
 }
 
+// updateStatus writes the status of instance if it differs from the observed status,
+// which is updated to the written status afterwards
+func (r *ReconcileVerneMQ) updateStatus(ctx context.Context, instance *vernemqv1alpha1.VerneMQ, observed *vernemqv1alpha1.VerneMQStatus) error {
+	if equality.Semantic.DeepEqual(*observed, instance.Status) {
+		return nil
+	}
+	err := r.client.Status().Update(ctx, instance)
+	if err != nil {
+		return pkgerr.Wrap(err, "updating status failed")
+	}
+	instance.Status.DeepCopyInto(observed)
+	return nil
+}
+
 func (r *ReconcileVerneMQ) listPods(ctx context.Context, name string, namespace string) (*corev1.PodList, error) {
 	podList := &corev1.PodList{}
 	labelSelector := labels.SelectorFromSet(labelsForVerneMQ(name))
@@ -207,23 +507,31 @@ func (r *ReconcileVerneMQ) listPods(ctx context.Context, name string, namespace
 	return podList, nil
 }
 
-func (r *ReconcileVerneMQ) createOrUpdate(ctx context.Context, name string, namespace string, object client.Object) error {
+// createOrUpdate writes the object and records an Event on the instance if the
+// object was created or changed. The existing object is only read to take over its
+// resourceVersion, the desired object is written as is. The API server doesn't
+// persist updates that don't change the object, they keep the resourceVersion.
+func (r *ReconcileVerneMQ) createOrUpdate(ctx context.Context, instance *vernemqv1alpha1.VerneMQ, name string, namespace string, object client.Object) error {
 
 	key := types.NamespacedName{Name: name, Namespace: namespace}
-	err := r.client.Get(ctx, key, object)
+	// reading into object would discard the desired state
+	existing := object.DeepCopyObject().(client.Object)
+	err := r.client.Get(ctx, key, existing)
 	if err != nil && errors.IsNotFound(err) {
 		// define a new resource
 		err = r.client.Create(ctx, object)
 		if err != nil {
 			return pkgerr.Wrap(err, "failed to create object")
 		}
+		objectWrites.WithLabelValues(r.kindOf(object), "create").Inc()
 		r.logger.Info("created", "object", reflect.TypeOf(object))
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, "Created", "Created %s %s", r.kindOf(object), name)
 		return nil
 	} else if err != nil {
 		return pkgerr.Wrap(err, "failed to retrieve object")
 	} else {
 		a := meta.NewAccessor()
-		resourceVersion, err := a.ResourceVersion(object)
+		resourceVersion, err := a.ResourceVersion(existing)
 		if err != nil {
 			return pkgerr.Wrap(err, "coudln't extract resource version of object")
 		}
@@ -231,15 +539,37 @@ func (r *ReconcileVerneMQ) createOrUpdate(ctx context.Context, name string, name
 		if err != nil {
 			return pkgerr.Wrap(err, "coudln't set resource version on object")
 		}
+		if svc, ok := object.(*corev1.Service); ok {
+			// the allocated cluster IPs are immutable
+			existingSvc := existing.(*corev1.Service)
+			if svc.Spec.ClusterIP == "" {
+				svc.Spec.ClusterIP = existingSvc.Spec.ClusterIP
+				svc.Spec.ClusterIPs = existingSvc.Spec.ClusterIPs
+			}
+		}
 		err = r.client.Update(ctx, object)
 		if err != nil {
 			return pkgerr.Wrap(err, "failed to update object")
 		}
-		r.logger.Info("updated", "object", reflect.TypeOf(object))
+		// updates without changes keep the resource version
+		if object.GetResourceVersion() != resourceVersion {
+			objectWrites.WithLabelValues(r.kindOf(object), "update").Inc()
+			r.logger.Info("updated", "object", reflect.TypeOf(object))
+			r.recorder.Eventf(instance, corev1.EventTypeNormal, "Updated", "Updated %s %s", r.kindOf(object), name)
+		}
 		return nil
 	}
 }
 
+// kindOf returns the kind of the object as registered in the scheme
+func (r *ReconcileVerneMQ) kindOf(object client.Object) string {
+	gvk, err := apiutil.GVKForObject(object, r.scheme)
+	if err != nil {
+		return reflect.TypeOf(object).Elem().Name()
+	}
+	return gvk.Kind
+}
+
 func labelsForVerneMQ(name string) map[string]string {
 	return map[string]string{"app": "vernemq", "vernemq": name}
 }
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	"github.com/vernemq/vmq-operator/pkg/cuttlefish"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook for VerneMQ objects
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&vernemqv1alpha1.VerneMQ{}).
+		WithValidator(&verneMQValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-vmq-k8s-vernemq-com-v1alpha1-vernemq,mutating=false,failurePolicy=fail,sideEffects=None,groups=vmq.k8s.vernemq.com,resources=vernemqs,verbs=create;update,versions=v1alpha1,name=vvernemq.kb.io,admissionReviewVersions=v1
+
+// verneMQValidator rejects VerneMQ objects with an invalid configuration or with
+// config keys unknown to the cuttlefish schema of their VerneMQ version
+type verneMQValidator struct{}
+
+var _ admission.CustomValidator = &verneMQValidator{}
+
+func (v *verneMQValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return v.validate(obj)
+}
+
+func (v *verneMQValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return v.validate(newObj)
+}
+
+func (v *verneMQValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func (v *verneMQValidator) validate(obj runtime.Object) error {
+	instance, ok := obj.(*vernemqv1alpha1.VerneMQ)
+	if !ok {
+		return fmt.Errorf("expected a VerneMQ but got a %T", obj)
+	}
+	if err := ValidateConfig(instance); err != nil {
+		return err
+	}
+	// versions without an embedded schema can't be checked
+	unknown, err := UnknownConfigKeys(instance)
+	if err != nil && !errors.Is(err, cuttlefish.ErrNoSchema) {
+		return err
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("config keys not in the cuttlefish schema: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
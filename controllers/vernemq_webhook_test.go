@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerneMQValidator(t *testing.T) {
+	boolTrue := true
+	tests := []struct {
+		name      string
+		version   string
+		vmqConfig string
+		static    *vernemqv1alpha1.StaticConfig
+		auth      *vernemqv1alpha1.AuthSpec
+		wantErr   bool
+	}{
+		{
+			name:   "keys rendered by the operator",
+			static: &vernemqv1alpha1.StaticConfig{AllowAnonymous: &boolTrue, QueueType: "fifo"},
+			auth:   &vernemqv1alpha1.AuthSpec{UserSelector: &metav1.LabelSelector{}, ACLSelector: &metav1.LabelSelector{}},
+		},
+		{
+			name:      "unknown key",
+			vmqConfig: "max_inflight_mesages = 10",
+			wantErr:   true,
+		},
+		{
+			name:      "unknown key of a release without schema",
+			version:   "1.11.0",
+			vmqConfig: "max_inflight_mesages = 10",
+		},
+		{
+			name:      "key set by the operator",
+			vmqConfig: "metadata_plugin = vmq_plumtree",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				Spec: vernemqv1alpha1.VerneMQSpec{
+					Version:      tt.version,
+					VMQConfig:    tt.vmqConfig,
+					StaticConfig: tt.static,
+					Auth:         tt.auth,
+				},
+			}
+			err := (&verneMQValidator{}).validate(instance)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
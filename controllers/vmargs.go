@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// vmArg is a single line of vm.args, identified by its flag
+type vmArg struct {
+	flag  string
+	value string
+}
+
+// makeVMArgs renders the vm.args used by the VerneMQ pods. The defaults of the
+// operator are merged by flag with the lines of VMArgs, the typed ErlangVM
+// settings and the ErlangVM extra flags, in increasing order of precedence.
+func makeVMArgs(instance *vernemqv1alpha1.VerneMQ) string {
+	var b strings.Builder
+	for _, a := range mergedVMArgs(instance) {
+		if a.value == "" {
+			fmt.Fprintf(&b, "%s\n", a.flag)
+		} else {
+			fmt.Fprintf(&b, "%s %s\n", a.flag, a.value)
+		}
+	}
+	return b.String()
+}
+
+func mergedVMArgs(instance *vernemqv1alpha1.VerneMQ) []vmArg {
+	args := defaultVMArgs()
+	args = mergeVMArgs(args, parseVMArgs(instance.Spec.VMArgs))
+	args = mergeVMArgs(args, erlangVMArgs(instance.Spec.ErlangVM, instance.Spec.Resources))
+	if instance.Spec.ErlangVM != nil {
+		args = mergeVMArgs(args, parseVMArgs(strings.Join(instance.Spec.ErlangVM.ExtraFlags, "\n")))
+	}
+	return args
+}
+
+// schedulersEnv returns the ERLANG_SCHEDULERS variable with the CPU limit of the
+// VerneMQ container, which can be used to template +S in VMArgs. It is omitted if
+// vm.args already sets +S.
+func schedulersEnv(instance *vernemqv1alpha1.VerneMQ) []v1.EnvVar {
+	for _, a := range mergedVMArgs(instance) {
+		if a.flag == "+S" {
+			return nil
+		}
+	}
+	return []v1.EnvVar{{
+		Name: "ERLANG_SCHEDULERS",
+		ValueFrom: &v1.EnvVarSource{
+			ResourceFieldRef: &v1.ResourceFieldSelector{
+				ContainerName: vernemqName,
+				Resource:      "limits.cpu",
+			},
+		},
+	}}
+}
+
+func defaultVMArgs() []vmArg {
+	// -name is added by start script
+	return parseVMArgs(`+P 256000
+-env ERL_MAX_ETS_TABLES 256000
+-env ERL_CRASH_DUMP /vernemq/log/erl_crash.dump
+-env ERL_FULLSWEEP_AFTER 0
+-env ERL_MAX_PORTS 262144
++A 64
+-setcookie ${VMQ_DISTRIBUTED_COOKIE:-vmq}
+-name vmq@$VMQ_NODENAME.$VMQ_HOSTNAME
++K true
++W w
+-smp enable
+`)
+}
+
+func erlangVMArgs(c *vernemqv1alpha1.ErlangVMConfig, resources v1.ResourceRequirements) []vmArg {
+	var args []vmArg
+	var schedulers, schedulersOnline *int32
+	if cpu, ok := resources.Limits[v1.ResourceCPU]; ok {
+		// Value rounds up to the next full core
+		n := int32(cpu.Value())
+		schedulers, schedulersOnline = &n, &n
+	}
+	if c != nil {
+		if c.ProcessLimit != nil {
+			args = append(args, vmArg{"+P", fmt.Sprint(*c.ProcessLimit)})
+		}
+		if c.AsyncThreads != nil {
+			args = append(args, vmArg{"+A", fmt.Sprint(*c.AsyncThreads)})
+		}
+		if c.Schedulers != nil {
+			schedulers, schedulersOnline = c.Schedulers, c.Schedulers
+		}
+		if c.SchedulersOnline != nil {
+			schedulersOnline = c.SchedulersOnline
+		}
+		if c.SchedulerBusyWait != "" {
+			args = append(args, vmArg{"+sbwt", c.SchedulerBusyWait})
+		}
+		if c.DistributionBufferSize != nil {
+			args = append(args, vmArg{"+zdbbl", fmt.Sprint(*c.DistributionBufferSize)})
+		}
+		if c.KernelPoll != nil {
+			args = append(args, vmArg{"+K", fmt.Sprint(*c.KernelPoll)})
+		}
+	}
+	if schedulers != nil {
+		args = append(args, vmArg{"+S", fmt.Sprintf("%d:%d", *schedulers, *schedulersOnline)})
+	} else if schedulersOnline != nil {
+		args = append(args, vmArg{"+S", fmt.Sprintf(":%d", *schedulersOnline)})
+	}
+	return args
+}
+
+// validateSchedulers checks that no more schedulers are set online than started
+func validateSchedulers(instance *vernemqv1alpha1.VerneMQ) error {
+	c := instance.Spec.ErlangVM
+	if c == nil || c.SchedulersOnline == nil {
+		return nil
+	}
+	schedulers := c.Schedulers
+	if schedulers == nil {
+		cpu, ok := instance.Spec.Resources.Limits[v1.ResourceCPU]
+		if !ok {
+			return nil
+		}
+		n := int32(cpu.Value())
+		schedulers = &n
+	}
+	if *c.SchedulersOnline > *schedulers {
+		return fmt.Errorf("erlangVM.schedulersOnline (%d) must not exceed the number of schedulers (%d)", *c.SchedulersOnline, *schedulers)
+	}
+	return nil
+}
+
+// vmFlagsWithValue are the emulator and init flags that are followed by a value.
+// Any other flag with two arguments sets an application parameter.
+var vmFlagsWithValue = map[string]bool{
+	"-name":        true,
+	"-sname":       true,
+	"-setcookie":   true,
+	"-smp":         true,
+	"-boot":        true,
+	"-config":      true,
+	"-args_file":   true,
+	"-mode":        true,
+	"-pa":          true,
+	"-pz":          true,
+	"-eval":        true,
+	"-s":           true,
+	"-run":         true,
+	"-proto_dist":  true,
+	"-epmd_module": true,
+	"-connect_all": true,
+	"-hidden":      true,
+	"-heart":       true,
+}
+
+// parseVMArgs parses vm.args lines. Environment variables (-env NAME value) and
+// application parameters (-app key value) are identified by their flag and name,
+// all other lines by their flag.
+func parseVMArgs(vmArgs string) []vmArg {
+	var args []vmArg
+	for _, line := range strings.Split(vmArgs, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		n := 1
+		if fields[0] == "-env" || (len(fields) > 2 && strings.HasPrefix(fields[0], "-") && !vmFlagsWithValue[fields[0]]) {
+			n = 2
+		}
+		if n > len(fields) {
+			n = len(fields)
+		}
+		args = append(args, vmArg{
+			flag:  strings.Join(fields[:n], " "),
+			value: strings.Join(fields[n:], " "),
+		})
+	}
+	return args
+}
+
+// mergeVMArgs replaces the args with the same flag in place and appends the others
+func mergeVMArgs(args []vmArg, overrides []vmArg) []vmArg {
+	merged := append([]vmArg{}, args...)
+	for _, o := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].flag == o.flag {
+				merged[i] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
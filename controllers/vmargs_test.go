@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseVMArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		vmArgs string
+		want   []vmArg
+	}{
+		{
+			name:   "comments and blank lines",
+			vmArgs: "# comment\n\n  \n",
+		},
+		{
+			name:   "emulator flags",
+			vmArgs: "+P 256000\n+K true\n-smp enable\n-hidden",
+			want: []vmArg{
+				{"+P", "256000"},
+				{"+K", "true"},
+				{"-smp", "enable"},
+				{"-hidden", ""},
+			},
+		},
+		{
+			name:   "environment variables",
+			vmArgs: "-env ERL_MAX_PORTS 262144\n-env ERL_CRASH_DUMP",
+			want: []vmArg{
+				{"-env ERL_MAX_PORTS", "262144"},
+				{"-env ERL_CRASH_DUMP", ""},
+			},
+		},
+		{
+			name:   "application parameters",
+			vmArgs: "-kernel inet_dist_listen_min 9100\n-vmq_server max_drain_time 100",
+			want: []vmArg{
+				{"-kernel inet_dist_listen_min", "9100"},
+				{"-vmq_server max_drain_time", "100"},
+			},
+		},
+		{
+			name:   "flags with a value containing spaces",
+			vmArgs: "-name vmq@host extra\n-eval io:format(\"a b\")\n-pa /a /b",
+			want: []vmArg{
+				{"-name", "vmq@host extra"},
+				{"-eval", "io:format(\"a b\")"},
+				{"-pa", "/a /b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVMArgs(tt.vmArgs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVMArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeVMArgs(t *testing.T) {
+	args := []vmArg{{"+P", "256000"}, {"-env ERL_MAX_PORTS", "262144"}, {"+A", "64"}}
+	tests := []struct {
+		name      string
+		overrides []vmArg
+		want      []vmArg
+	}{
+		{
+			name: "no overrides",
+			want: args,
+		},
+		{
+			name:      "replaces in place",
+			overrides: []vmArg{{"-env ERL_MAX_PORTS", "1024"}},
+			want:      []vmArg{{"+P", "256000"}, {"-env ERL_MAX_PORTS", "1024"}, {"+A", "64"}},
+		},
+		{
+			name:      "appends new flags",
+			overrides: []vmArg{{"-env ERL_FULLSWEEP_AFTER", "0"}, {"+sbt", "db"}},
+			want:      append(append([]vmArg{}, args...), vmArg{"-env ERL_FULLSWEEP_AFTER", "0"}, vmArg{"+sbt", "db"}),
+		},
+		{
+			name:      "last override wins",
+			overrides: []vmArg{{"+A", "8"}, {"+A", "16"}},
+			want:      []vmArg{{"+P", "256000"}, {"-env ERL_MAX_PORTS", "262144"}, {"+A", "16"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeVMArgs(args, tt.overrides); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeVMArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !reflect.DeepEqual(args, []vmArg{{"+P", "256000"}, {"-env ERL_MAX_PORTS", "262144"}, {"+A", "64"}}) {
+		t.Errorf("mergeVMArgs() modified its input: %v", args)
+	}
+}
+
+func TestSchedulers(t *testing.T) {
+	two, four := int32(2), int32(4)
+	cpuLimit := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m")}}
+	tests := []struct {
+		name      string
+		erlangVM  *vernemqv1alpha1.ErlangVMConfig
+		resources v1.ResourceRequirements
+		want      string
+		wantEnv   bool
+		wantErr   bool
+	}{
+		{
+			name:    "no limit and no settings",
+			wantEnv: true,
+		},
+		{
+			name:      "from the CPU limit",
+			resources: cpuLimit,
+			want:      "+S 2:2\n",
+		},
+		{
+			name:      "schedulers online below the CPU limit",
+			erlangVM:  &vernemqv1alpha1.ErlangVMConfig{SchedulersOnline: &two},
+			resources: cpuLimit,
+			want:      "+S 2:2\n",
+		},
+		{
+			name:      "schedulers online above the CPU limit",
+			erlangVM:  &vernemqv1alpha1.ErlangVMConfig{SchedulersOnline: &four},
+			resources: cpuLimit,
+			wantErr:   true,
+		},
+		{
+			name:     "schedulers online above the schedulers",
+			erlangVM: &vernemqv1alpha1.ErlangVMConfig{Schedulers: &two, SchedulersOnline: &four},
+			wantErr:  true,
+		},
+		{
+			name:     "schedulers online only",
+			erlangVM: &vernemqv1alpha1.ErlangVMConfig{SchedulersOnline: &four},
+			want:     "+S :4\n",
+		},
+		{
+			name:     "set via extra flags",
+			erlangVM: &vernemqv1alpha1.ErlangVMConfig{ExtraFlags: []string{"+S 8:8"}},
+			want:     "+S 8:8\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				Spec: vernemqv1alpha1.VerneMQSpec{ErlangVM: tt.erlangVM, Resources: tt.resources},
+			}
+			err := validateSchedulers(instance)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateSchedulers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.want != "" && !strings.Contains(makeVMArgs(instance), tt.want) {
+				t.Errorf("makeVMArgs() = %q, want it to contain %q", makeVMArgs(instance), tt.want)
+			}
+			if got := len(schedulersEnv(instance)) > 0; got != tt.wantEnv {
+				t.Errorf("schedulersEnv() set = %v, want %v", got, tt.wantEnv)
+			}
+		})
+	}
+}
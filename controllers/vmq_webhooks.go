@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const webhooksPluginName = "vmq_webhooks"
+
+// endpointCredentialsRegexp matches the user info of URLs in vmq-admin commands
+var endpointCredentialsRegexp = regexp.MustCompile(`://[^/@\s"]+@`)
+
+// webhookFileName returns the key of the endpoint of the i-th webhook in the config
+// Secret. It contains the resource version of the basic auth Secret, so that new
+// credentials change the reloadable config and the webhook is registered again.
+func webhookFileName(i int, secret *v1.Secret) string {
+	return fmt.Sprintf("webhook%d-%s", i+1, secret.ResourceVersion)
+}
+
+// mergeWebhooks adds the vmq_webhooks plugin to the reloadable config of the
+// instance, so that webhooks are added and removed without restarting VerneMQ.
+// The webhooks are registered after the plugin is started and deregistered before
+// it is stopped, so vmq_k8s replaces them whenever they change. The endpoints of
+// webhooks with basic auth are returned as files of the config Secret, which the
+// commands read when they run. The files of the config applied before are kept,
+// the nodes deregister their webhooks with them.
+func (r *ReconcileVerneMQ) mergeWebhooks(ctx context.Context, instance *vernemqv1alpha1.VerneMQ) (map[string]string, error) {
+	if len(instance.Spec.Webhooks) == 0 {
+		return nil, nil
+	}
+	for _, p := range instance.Spec.Config.Plugins {
+		if p.Name == webhooksPluginName {
+			return nil, fmt.Errorf("plugin %s is already declared by spec.config.plugins, it can't be combined with spec.webhooks", webhooksPluginName)
+		}
+	}
+
+	files := map[string]string{}
+	plugin := vernemqv1alpha1.Plugin{Name: webhooksPluginName}
+	for i, w := range instance.Spec.Webhooks {
+		endpoint, err := url.Parse(w.Endpoint)
+		if err != nil {
+			return nil, pkgerr.Wrapf(err, "invalid endpoint of %s webhook", w.Hook)
+		}
+		if endpoint.User != nil {
+			return nil, fmt.Errorf("endpoint of %s webhook must not contain credentials, use basicAuthSecretRef", w.Hook)
+		}
+		value := shellQuote(w.Endpoint)
+		if w.BasicAuthSecretRef != nil {
+			secret := &v1.Secret{}
+			err := r.client.Get(ctx, types.NamespacedName{Name: w.BasicAuthSecretRef.Name, Namespace: instance.Namespace}, secret)
+			if err != nil {
+				return nil, pkgerr.Wrapf(err, "get basic auth of %s webhook", w.Hook)
+			}
+			endpoint.User = url.UserPassword(string(secret.Data["username"]), string(secret.Data["password"]))
+			name := webhookFileName(i, secret)
+			files[name] = endpoint.String()
+			value = fmt.Sprintf(`"$(cat %s)"`, path.Join(configmapsDir, name))
+		}
+		base64Payload := true
+		if w.Base64Payload != nil {
+			base64Payload = *w.Base64Payload
+		}
+		plugin.PostStart = append(plugin.PostStart, vernemqv1alpha1.Command{
+			Command: fmt.Sprintf("vmq-admin webhooks register hook=%s endpoint=%s --base64payload=%t --no_payload=%t", w.Hook, value, base64Payload, w.NoPayload),
+		})
+		plugin.PreStop = append(plugin.PreStop, vernemqv1alpha1.Command{
+			Command: fmt.Sprintf("vmq-admin webhooks deregister hook=%s endpoint=%s", w.Hook, value),
+		})
+	}
+	instance.Spec.Config.Plugins = append(instance.Spec.Config.Plugins, plugin)
+
+	existing := &v1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: configSecretName, Namespace: instance.Namespace}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, pkgerr.Wrap(err, "get config secret")
+	}
+	applied := string(existing.Data[configFileName])
+	for name, endpoint := range existing.Data {
+		if _, ok := files[name]; !ok && name != configFileName && strings.Contains(applied, path.Join(configmapsDir, name)) {
+			files[name] = string(endpoint)
+		}
+	}
+	return files, nil
+}
+
+// redactCredentials removes the credentials of URLs from commands reported in
+// the status
+func redactCredentials(command string) string {
+	return endpointCredentialsRegexp.ReplaceAllString(command, "://***@")
+}
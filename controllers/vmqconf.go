@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	pkgerr "github.com/pkg/errors"
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+)
+
+// confEntry is a single `key = value` line of vernemq.conf
+type confEntry struct {
+	key   string
+	value string
+}
+
+// makeVerneMQConf renders the vernemq.conf used by the VerneMQ pods. It is made
+// of the settings the operator depends on, the typed StaticConfig and the free-form
+// VMQConfig. VMQConfig may override the operator defaults, but must not repeat keys
+// that are owned by the operator or set via StaticConfig.
+func makeVerneMQConf(instance *vernemqv1alpha1.VerneMQ) (string, error) {
+	owned := operatorConfEntries(instance)
+	typed := staticConfEntries(instance.Spec.StaticConfig)
+	custom, err := parseConfEntries(instance.Spec.VMQConfig)
+	if err != nil {
+		return "", pkgerr.Wrap(err, "parse vmqConfig")
+	}
+
+	reserved := map[string]string{}
+	for _, e := range owned {
+		reserved[e.key] = "the operator"
+	}
+	for _, e := range typed {
+		reserved[e.key] = "staticConfig"
+	}
+	seen := map[string]bool{}
+	for _, e := range custom {
+		if origin, ok := reserved[e.key]; ok {
+			return "", pkgerr.Errorf("vmqConfig key %q is already set by %s", e.key, origin)
+		}
+		if seen[e.key] {
+			return "", pkgerr.Errorf("vmqConfig key %q is set more than once", e.key)
+		}
+		seen[e.key] = true
+	}
+
+	entries := owned
+	for _, e := range defaultConfEntries() {
+		if _, ok := reserved[e.key]; ok || seen[e.key] {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	entries = append(entries, typed...)
+	entries = append(entries, custom...)
+	return renderConfEntries(entries), nil
+}
+
+// operatorConfEntries returns the static configuration the operator relies on.
+// These keys can't be changed by the user.
+func operatorConfEntries(instance *vernemqv1alpha1.VerneMQ) []confEntry {
+	entries := []confEntry{
+		{"metadata_plugin", "vmq_swc"},
+		{"listener.vmq.clustering", "$MY_POD_IP:44053"},
+		{"listener.http.default", "0.0.0.0:8888"},
+	}
+	entries = append(entries, authConfEntries(instance)...)
+	return append(entries,
+		confEntry{"plugins.vmq_k8s.path", "/vernemq/plugins/_build/default"},
+		confEntry{"plugins.vmq_k8s", "on"},
+	)
+}
+
+// defaultConfEntries returns the defaults of the operator that can be
+// overridden via StaticConfig or VMQConfig.
+func defaultConfEntries() []confEntry {
+	return []confEntry{
+		{"leveldb.maximum_memory.percent", "20"},
+		{"log.console", "console"},
+	}
+}
+
+func staticConfEntries(c *vernemqv1alpha1.StaticConfig) []confEntry {
+	if c == nil {
+		return nil
+	}
+	var entries []confEntry
+	if c.AllowAnonymous != nil {
+		entries = append(entries, confEntry{"allow_anonymous", onOff(*c.AllowAnonymous)})
+	}
+	if c.MaxInflightMessages != nil {
+		entries = append(entries, confEntry{"max_inflight_messages", fmt.Sprint(*c.MaxInflightMessages)})
+	}
+	if c.MaxOnlineMessages != nil {
+		entries = append(entries, confEntry{"max_online_messages", fmt.Sprint(*c.MaxOnlineMessages)})
+	}
+	if c.MaxOfflineMessages != nil {
+		entries = append(entries, confEntry{"max_offline_messages", fmt.Sprint(*c.MaxOfflineMessages)})
+	}
+	if c.QueueType != "" {
+		entries = append(entries, confEntry{"queue_type", c.QueueType})
+	}
+	if c.QueueDeliverMode != "" {
+		entries = append(entries, confEntry{"queue_deliver_mode", c.QueueDeliverMode})
+	}
+	if c.PersistentClientExpiration != "" {
+		entries = append(entries, confEntry{"persistent_client_expiration", c.PersistentClientExpiration})
+	}
+	if c.SharedSubscriptionPolicy != "" {
+		entries = append(entries, confEntry{"shared_subscription_policy", c.SharedSubscriptionPolicy})
+	}
+	if l := c.LevelDB; l != nil {
+		if l.MaximumMemoryPercent != nil {
+			entries = append(entries, confEntry{"leveldb.maximum_memory.percent", fmt.Sprint(*l.MaximumMemoryPercent)})
+		}
+		if l.WriteBufferSizeMin != nil {
+			entries = append(entries, confEntry{"leveldb.write_buffer_size_min", fmt.Sprint(*l.WriteBufferSizeMin)})
+		}
+		if l.WriteBufferSizeMax != nil {
+			entries = append(entries, confEntry{"leveldb.write_buffer_size_max", fmt.Sprint(*l.WriteBufferSizeMax)})
+		}
+	}
+	if l := c.Logging; l != nil {
+		if l.Console != "" {
+			entries = append(entries, confEntry{"log.console", l.Console})
+		}
+		if l.ConsoleLevel != "" {
+			entries = append(entries, confEntry{"log.console.level", l.ConsoleLevel})
+		}
+		if l.CrashLog != nil {
+			entries = append(entries, confEntry{"log.crash", onOff(*l.CrashLog)})
+		}
+	}
+	return entries
+}
+
+// parseConfEntries parses vernemq.conf formatted config, skipping empty lines and comments
+func parseConfEntries(config string) ([]confEntry, error) {
+	var entries []confEntry
+	for i, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, pkgerr.Errorf("line %d: expected `key = value`, got %q", i+1, line)
+		}
+		entries = append(entries, confEntry{strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])})
+	}
+	return entries, nil
+}
+
+// hasConfKey returns true if the config sets the key. Invalid configs are
+// reported when the vernemq.conf is rendered.
+func hasConfKey(config string, key string) bool {
+	entries, err := parseConfEntries(config)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if e.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func renderConfEntries(entries []confEntry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s = %s\n", e.key, e.value)
+	}
+	return b.String()
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	vernemqv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
+)
+
+func TestParseConfEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []confEntry
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			config: "",
+		},
+		{
+			name:   "comments and blank lines",
+			config: "# a comment\n\n   \n  # indented comment\n",
+		},
+		{
+			name:   "trims keys and values",
+			config: "  allow_anonymous   =  on  \nlistener.tcp.default=0.0.0.0:1883",
+			want: []confEntry{
+				{"allow_anonymous", "on"},
+				{"listener.tcp.default", "0.0.0.0:1883"},
+			},
+		},
+		{
+			name:   "value containing equal signs",
+			config: "vmq_webhooks.hook.endpoint = http://host/?a=b",
+			want:   []confEntry{{"vmq_webhooks.hook.endpoint", "http://host/?a=b"}},
+		},
+		{
+			name:   "empty value",
+			config: "log.syslog =",
+			want:   []confEntry{{"log.syslog", ""}},
+		},
+		{
+			name:    "missing separator",
+			config:  "allow_anonymous on",
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			config:  " = on",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfEntries(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConfEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseConfEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeVerneMQConf(t *testing.T) {
+	anonymous := true
+	percent := int32(40)
+	tests := []struct {
+		name         string
+		staticConfig *vernemqv1alpha1.StaticConfig
+		vmqConfig    string
+		contains     []string
+		excludes     []string
+		wantErr      string
+	}{
+		{
+			name:     "operator defaults",
+			contains: []string{"metadata_plugin = vmq_swc\n", "leveldb.maximum_memory.percent = 20\n", "log.console = console\n"},
+		},
+		{
+			name:      "vmqConfig overrides a default",
+			vmqConfig: "log.console = file",
+			contains:  []string{"log.console = file\n"},
+			excludes:  []string{"log.console = console\n"},
+		},
+		{
+			name:         "staticConfig overrides a default",
+			staticConfig: &vernemqv1alpha1.StaticConfig{LevelDB: &vernemqv1alpha1.LevelDBConfig{MaximumMemoryPercent: &percent}},
+			contains:     []string{"leveldb.maximum_memory.percent = 40\n"},
+			excludes:     []string{"leveldb.maximum_memory.percent = 20\n"},
+		},
+		{
+			name:      "vmqConfig sets an operator key",
+			vmqConfig: "metadata_plugin = vmq_plumtree",
+			wantErr:   `vmqConfig key "metadata_plugin" is already set by the operator`,
+		},
+		{
+			name:         "vmqConfig repeats a staticConfig key",
+			staticConfig: &vernemqv1alpha1.StaticConfig{AllowAnonymous: &anonymous},
+			vmqConfig:    "allow_anonymous = off",
+			wantErr:      `vmqConfig key "allow_anonymous" is already set by staticConfig`,
+		},
+		{
+			name:      "vmqConfig sets a key twice",
+			vmqConfig: "max_online_messages = 10\nmax_online_messages = 20",
+			wantErr:   `vmqConfig key "max_online_messages" is set more than once`,
+		},
+		{
+			name:      "malformed vmqConfig",
+			vmqConfig: "allow_anonymous",
+			wantErr:   "parse vmqConfig",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &vernemqv1alpha1.VerneMQ{
+				Spec: vernemqv1alpha1.VerneMQSpec{
+					StaticConfig: tt.staticConfig,
+					VMQConfig:    tt.vmqConfig,
+				},
+			}
+			got, err := makeVerneMQConf(instance)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("makeVerneMQConf() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("makeVerneMQConf() error = %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("makeVerneMQConf() = %q, want it to contain %q", got, s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(got, s) {
+					t.Errorf("makeVerneMQConf() = %q, want it to not contain %q", got, s)
+				}
+			}
+		})
+	}
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -30,9 +33,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
 
 	vmqk8sv1alpha1 "github.com/vernemq/vmq-operator/api/v1alpha1"
 	"github.com/vernemq/vmq-operator/controllers"
+	"github.com/vernemq/vmq-operator/pkg/cuttlefish"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -49,6 +54,10 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
@@ -94,6 +103,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "VerneMQ")
 		os.Exit(1)
 	}
+	// The webhook server needs a serving certificate, see config/default/manager_webhook_patch.yaml
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = controllers.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "VerneMQ")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -111,3 +127,55 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// validateConfig implements the `validate-config` subcommand, which checks the
+// configuration of VerneMQ manifests offline and returns the exit code.
+func validateConfig(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	strict := fs.Bool("strict", false, "Fail on config keys that are not in the cuttlefish schema")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate-config [-strict] <vernemq.yaml>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	exitCode := 0
+	for _, file := range fs.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		instance := &vmqk8sv1alpha1.VerneMQ{}
+		if err := yaml.UnmarshalStrict(data, instance); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		if err := controllers.ValidateConfig(instance); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		unknown, err := controllers.UnknownConfigKeys(instance)
+		if err != nil && !errors.Is(err, cuttlefish.ErrNoSchema) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		if len(unknown) > 0 {
+			fmt.Fprintf(os.Stderr, "%s: config keys not in the cuttlefish schema: %s\n", file, strings.Join(unknown, ", "))
+			if *strict {
+				exitCode = 1
+				continue
+			}
+		}
+		fmt.Printf("%s: ok\n", file)
+	}
+	return exitCode
+}
@@ -0,0 +1,139 @@
+// Package cuttlefish validates vernemq.conf keys offline against the cuttlefish
+// schemas of the supported VerneMQ versions.
+package cuttlefish
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-semver/semver"
+	pkgerr "github.com/pkg/errors"
+)
+
+//go:embed schemas/*.schema
+var schemaFS embed.FS
+
+var mappingRegexp = regexp.MustCompile(`\{mapping,\s*"([^"]+)"`)
+
+var (
+	schemasOnce sync.Once
+	schemas     []*Schema
+	schemasErr  error
+)
+
+// ErrNoSchema is returned by Lookup if no schema is embedded for a VerneMQ version
+var ErrNoSchema = pkgerr.New("no cuttlefish schema available")
+
+// Schema holds the vernemq.conf keys accepted by a VerneMQ version
+type Schema struct {
+	// Version is the VerneMQ release the schema applies to, with a zero patch version
+	Version  semver.Version
+	mappings [][]string
+}
+
+// UnknownKeysError lists the keys that are not part of a schema
+type UnknownKeysError struct {
+	Version semver.Version
+	Keys    []string
+}
+
+func (e *UnknownKeysError) Error() string {
+	return fmt.Sprintf("unknown config keys for VerneMQ %d.%d: %s",
+		e.Version.Major, e.Version.Minor, strings.Join(e.Keys, ", "))
+}
+
+// Lookup returns the schema for the major and minor release of the given VerneMQ
+// version. Releases without an embedded schema return ErrNoSchema, as the keys
+// accepted by another release can't be assumed.
+func Lookup(version string) (*Schema, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "parse version")
+	}
+	schemasOnce.Do(func() {
+		schemas, schemasErr = loadSchemas()
+	})
+	if schemasErr != nil {
+		return nil, schemasErr
+	}
+	for _, s := range schemas {
+		if s.Version.Major == v.Major && s.Version.Minor == v.Minor {
+			return s, nil
+		}
+	}
+	return nil, pkgerr.Wrapf(ErrNoSchema, "VerneMQ %s", version)
+}
+
+// Validate checks that all keys are accepted by the schema
+func (s *Schema) Validate(keys []string) error {
+	unknown := map[string]bool{}
+	for _, k := range keys {
+		if !s.Contains(k) {
+			unknown[k] = true
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	err := &UnknownKeysError{Version: s.Version}
+	for k := range unknown {
+		err.Keys = append(err.Keys, k)
+	}
+	sort.Strings(err.Keys)
+	return err
+}
+
+// Contains reports whether key matches a mapping of the schema. Variable
+// segments of a mapping such as `$name` match any single segment of the key.
+func (s *Schema) Contains(key string) bool {
+	segments := strings.Split(key, ".")
+	for _, m := range s.mappings {
+		if matchSegments(m, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(mapping []string, segments []string) bool {
+	if len(mapping) != len(segments) {
+		return false
+	}
+	for i, m := range mapping {
+		if !strings.HasPrefix(m, "$") && m != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func loadSchemas() ([]*Schema, error) {
+	files, err := schemaFS.ReadDir("schemas")
+	if err != nil {
+		return nil, pkgerr.Wrap(err, "read embedded schemas")
+	}
+	var loaded []*Schema
+	for _, f := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(f.Name(), "vernemq-"), ".schema")
+		v, err := semver.NewVersion(name + ".0")
+		if err != nil {
+			return nil, pkgerr.Wrapf(err, "parse version of schema %s", f.Name())
+		}
+		data, err := schemaFS.ReadFile(path.Join("schemas", f.Name()))
+		if err != nil {
+			return nil, pkgerr.Wrapf(err, "read schema %s", f.Name())
+		}
+		s := &Schema{Version: *v}
+		for _, m := range mappingRegexp.FindAllStringSubmatch(string(data), -1) {
+			s.mappings = append(s.mappings, strings.Split(m[1], "."))
+		}
+		loaded = append(loaded, s)
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Version.LessThan(loaded[j].Version) })
+	return loaded, nil
+}
@@ -0,0 +1,95 @@
+package cuttlefish
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr error
+	}{
+		{version: "1.12.0", want: "1.12.0"},
+		{version: "1.12.6-alpine", want: "1.12.0"},
+		{version: "1.13.0", want: "1.13.0"},
+		{version: "1.11.0", wantErr: ErrNoSchema},
+		{version: "1.14.0", wantErr: ErrNoSchema},
+		{version: "2.12.0", wantErr: ErrNoSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			s, err := Lookup(tt.version)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Lookup(%q) error = %v, want %v", tt.version, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", tt.version, err)
+			}
+			if got := s.Version.String(); got != tt.want {
+				t.Errorf("Lookup(%q) = %s, want %s", tt.version, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Lookup("latest"); err == nil || errors.Is(err, ErrNoSchema) {
+		t.Errorf("Lookup(\"latest\") error = %v, want a parse error", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := Lookup("1.12.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{
+			name: "no keys",
+		},
+		{
+			name: "fixed keys",
+			keys: []string{"allow_anonymous", "queue_type", "max_inflight_messages"},
+		},
+		{
+			name: "variable segments",
+			keys: []string{"listener.tcp.default", "listener.ssl.internal.cafile"},
+		},
+		{
+			name: "variable segments match exactly one segment",
+			keys: []string{"listener.tcp", "listener.tcp.default.extra.cafile"},
+			want: []string{"listener.tcp", "listener.tcp.default.extra.cafile"},
+		},
+		{
+			name: "unknown keys are sorted and reported once",
+			keys: []string{"zzz", "allow_anonymous", "aaa", "zzz"},
+			want: []string{"aaa", "zzz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(tt.keys)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			var unknown *UnknownKeysError
+			if !errors.As(err, &unknown) {
+				t.Fatalf("Validate() error = %v, want an UnknownKeysError", err)
+			}
+			if !reflect.DeepEqual(unknown.Keys, tt.want) {
+				t.Errorf("Validate() unknown keys = %v, want %v", unknown.Keys, tt.want)
+			}
+		})
+	}
+}